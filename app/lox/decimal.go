@@ -0,0 +1,144 @@
+package lox
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// decimalDivisionScale is the number of decimal places kept when the quotient of two decimals does not terminate, like 1d / 3.
+// The quotient is rounded half to even.
+const decimalDivisionScale = 32
+
+// maxDecimalScale limits the exponent and the number of decimal places of the parsed decimals, in both directions.
+// Without it, a short literal like 1e1000000000d would need a billion digits.
+const maxDecimalScale = 10_000
+
+// decimal is an exact decimal number, the value is `unscaled * 10^-scale`.
+// The scale is kept as it was written, so 1.10d prints as 1.10 and not as 1.1.
+type decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// parseDecimal parses the digits of a decimal literal, like "-12.50" or "1.5e3".
+// The exponent and the scale of the result must be within maxDecimalScale.
+func parseDecimal(s string) (decimal, bool) {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		mantissa = s[:i]
+		exponent, err = strconv.Atoi(s[i+1:])
+		if err != nil || exponent < -maxDecimalScale || exponent > maxDecimalScale {
+			return decimal{}, false
+		}
+	}
+
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	unscaled, ok := new(big.Int).SetString(integer+fraction, 10)
+	if !ok || strings.ContainsAny(fraction, "+-") {
+		return decimal{}, false
+	}
+
+	d := decimal{unscaled: unscaled, scale: len(fraction) - exponent}
+	if d.scale < -maxDecimalScale || d.scale > maxDecimalScale {
+		return decimal{}, false
+	}
+	if d.scale < 0 {
+		d = d.rescale(0)
+	}
+
+	return d, true
+}
+
+func decimalFromInt(i *big.Int) decimal {
+	return decimal{unscaled: new(big.Int).Set(i), scale: 0}
+}
+
+// decimalFromRat converts the exact fraction into a decimal with at least `scale` decimal places.
+// If the fraction does not terminate, it is rounded to `decimalDivisionScale` places.
+func decimalFromRat(r *big.Rat, scale int) decimal {
+	for s := scale; s < max(scale, decimalDivisionScale); s++ {
+		numerator := new(big.Int).Mul(r.Num(), pow10(s))
+		quotient, remainder := new(big.Int).QuoRem(numerator, r.Denom(), new(big.Int))
+		if remainder.Sign() == 0 {
+			return decimal{unscaled: quotient, scale: s}
+		}
+	}
+
+	s := max(scale, decimalDivisionScale)
+	numerator := new(big.Int).Mul(r.Num(), pow10(s))
+	return decimal{unscaled: roundHalfEven(numerator, r.Denom()), scale: s}
+}
+
+// roundHalfEven divides the numerator by the positive denominator and rounds the quotient half to even.
+func roundHalfEven(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+
+	twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	c := twice.Cmp(denominator)
+	if c > 0 || (c == 0 && quotient.Bit(0) == 1) {
+		if numerator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// rescale returns the same value with more decimal places.
+func (d decimal) rescale(scale int) decimal {
+	if scale == d.scale {
+		return d
+	}
+
+	if scale > d.scale {
+		return decimal{unscaled: new(big.Int).Mul(d.unscaled, pow10(scale-d.scale)), scale: scale}
+	}
+
+	return decimal{unscaled: new(big.Int).Quo(d.unscaled, pow10(d.scale-scale)), scale: scale}
+}
+
+// align returns both decimals with the same scale, so their unscaled values can be compared or added.
+func align(a, b decimal) (decimal, decimal) {
+	scale := max(a.scale, b.scale)
+	return a.rescale(scale), b.rescale(scale)
+}
+
+func (d decimal) rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.unscaled, pow10(d.scale))
+}
+
+func (d decimal) cmp(other decimal) int {
+	a, b := align(d, other)
+	return a.unscaled.Cmp(b.unscaled)
+}
+
+func (d decimal) String() string {
+	digits := new(big.Int).Abs(d.unscaled).String()
+	sign := ""
+	if d.unscaled.Sign() < 0 {
+		sign = "-"
+	}
+
+	if d.scale == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+}
+
+// MarshalJSON writes the decimal as a JSON number with all its decimal places.
+func (d decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
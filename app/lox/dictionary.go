@@ -0,0 +1,173 @@
+package lox
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// dictionary is the value of a map literal, like `{"a": 1, b: 2}`.
+// The entries are kept in insertion order, which is the order they are printed and iterated in.
+// Same as the lists, it is a pointer, so the changes made through one reference are seen by all the others.
+type dictionary struct {
+	entries []entry
+	// index maps the hash keys to their position in the entries.
+	index map[hashKey]int
+}
+
+type entry struct {
+	key   any
+	value any
+}
+
+// hashKey identifies a key, two keys have the same hashKey exactly when isEqual says they are equal.
+// So `1`, `1.0`, `1n` and `1.0d` are all the same key, like they are equal with `==`.
+type hashKey struct {
+	kind  string
+	value string
+}
+
+func newDictionary() *dictionary {
+	return &dictionary{index: map[hashKey]int{}}
+}
+
+func keyOf(key any) (hashKey, error) {
+	switch key := key.(type) {
+	case nil:
+		return hashKey{kind: "nil"}, nil
+	case bool:
+		return hashKey{kind: "bool", value: strconv.FormatBool(key)}, nil
+	case string:
+		return hashKey{kind: "string", value: key}, nil
+	case float64:
+		if math.IsNaN(key) {
+			// NaN is not equal to anything, not even itself, so it could never be found again.
+			return hashKey{}, errors.New("NaN cannot be a map key.")
+		}
+		if math.IsInf(key, 0) {
+			return hashKey{kind: "number", value: strconv.FormatFloat(key, 'f', -1, 64)}, nil
+		}
+		return hashKey{kind: "number", value: toRat(key).RatString()}, nil
+	case int64, *big.Int, decimal:
+		// The exact fraction is the same whatever the type of the number is.
+		return hashKey{kind: "number", value: toRat(key).RatString()}, nil
+	default:
+		return hashKey{}, errors.New("Map keys must be numbers, strings, booleans or nil.")
+	}
+}
+
+// get returns nil when the key is missing, so the default value can be given with `??`.
+func (d *dictionary) get(key any) (any, error) {
+	k, err := keyOf(key)
+	if err != nil {
+		return nil, err
+	}
+
+	i, found := d.index[k]
+	if !found {
+		return nil, nil
+	}
+
+	return d.entries[i].value, nil
+}
+
+// set replaces the value of an existing key in place, the key keeps its position and the way it was first written.
+func (d *dictionary) set(key, value any) error {
+	k, err := keyOf(key)
+	if err != nil {
+		return err
+	}
+
+	if i, found := d.index[k]; found {
+		d.entries[i].value = value
+		return nil
+	}
+
+	d.index[k] = len(d.entries)
+	d.entries = append(d.entries, entry{key: key, value: value})
+	return nil
+}
+
+func (d *dictionary) String() string {
+	entries := make([]string, len(d.entries))
+	for i, e := range d.entries {
+		entries[i] = stringifyElement(e.key) + ": " + stringifyElement(e.value)
+	}
+
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+// method returns the native method bound to the map, like the `has` in `config.has("port")`.
+func (d *dictionary) method(name string) (*nativeFunction, bool) {
+	switch name {
+	case "has":
+		return &nativeFunction{parameters: 1, fn: d.has}, true
+	case "keys":
+		return &nativeFunction{parameters: 0, fn: d.keys}, true
+	case "values":
+		return &nativeFunction{parameters: 0, fn: d.values}, true
+	case "delete":
+		return &nativeFunction{parameters: 1, fn: d.delete}, true
+	case "len":
+		return &nativeFunction{parameters: 0, fn: d.len}, true
+	default:
+		return nil, false
+	}
+}
+
+func (d *dictionary) has(arguments []any) (any, error) {
+	k, err := keyOf(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	_, found := d.index[k]
+	return found, nil
+}
+
+func (d *dictionary) keys(arguments []any) (any, error) {
+	keys := make([]any, len(d.entries))
+	for i, e := range d.entries {
+		keys[i] = e.key
+	}
+
+	return &list{elements: keys}, nil
+}
+
+func (d *dictionary) values(arguments []any) (any, error) {
+	values := make([]any, len(d.entries))
+	for i, e := range d.entries {
+		values[i] = e.value
+	}
+
+	return &list{elements: values}, nil
+}
+
+// delete removes the key and returns whether it was in the map.
+func (d *dictionary) delete(arguments []any) (any, error) {
+	k, err := keyOf(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	i, found := d.index[k]
+	if !found {
+		return false, nil
+	}
+
+	d.entries = append(d.entries[:i], d.entries[i+1:]...)
+	delete(d.index, k)
+	for key, position := range d.index {
+		if position > i {
+			d.index[key] = position - 1
+		}
+	}
+
+	return true, nil
+}
+
+func (d *dictionary) len(arguments []any) (any, error) {
+	return int64(len(d.entries)), nil
+}
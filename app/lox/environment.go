@@ -0,0 +1,39 @@
+package lox
+
+// environment holds the variables of a scope, like the variables of a `for-in` loop.
+// The variables that are not found in any scope are looked up in the natives.
+type environment struct {
+	values    map[string]any
+	enclosing *environment
+}
+
+func newEnvironment(enclosing *environment) *environment {
+	return &environment{values: map[string]any{}, enclosing: enclosing}
+}
+
+func (e *environment) define(name string, value any) {
+	e.values[name] = value
+}
+
+// get looks up the variable from the innermost scope outwards, the nil environment is the empty global scope.
+func (e *environment) get(name string) (any, bool) {
+	for scope := e; scope != nil; scope = scope.enclosing {
+		if value, found := scope.values[name]; found {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// assign changes the variable in the innermost scope that has it, and reports whether there is one.
+func (e *environment) assign(name string, value any) bool {
+	for scope := e; scope != nil; scope = scope.enclosing {
+		if _, found := scope.values[name]; found {
+			scope.values[name] = value
+			return true
+		}
+	}
+
+	return false
+}
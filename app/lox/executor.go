@@ -0,0 +1,100 @@
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+type Script struct {
+	Name   string
+	Source io.Reader
+}
+
+type ScriptResult struct {
+	Name   string
+	Output string
+	Err    error
+}
+
+type ScriptError struct {
+	Name string
+	Err  error
+}
+
+func (se ScriptError) Error() string {
+	return fmt.Sprintf("%s: %v", se.Name, se.Err)
+}
+
+func (se ScriptError) Unwrap() error {
+	return se.Err
+}
+
+// Executor runs many scripts in parallel.
+// Every script runs on its own Lox instance, so the scripts do not share any state.
+type Executor struct {
+	workers int
+	opts    []Option
+}
+
+// NewExecutor creates an executor that runs at most `workers` scripts at the same time.
+// If `workers` is not positive, the number of CPUs is used instead.
+// The options are applied to the Lox instance of every script, but the stdout is always replaced with the script's own buffer.
+func NewExecutor(workers int, opts ...Option) *Executor {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return &Executor{workers: workers, opts: opts}
+}
+
+// Execute runs the scripts and returns their results in the same order as the scripts.
+// The returned error joins the errors of all the scripts that failed, each wrapped in a ScriptError.
+func (e *Executor) Execute(scripts []Script) ([]ScriptResult, error) {
+	results := make([]ScriptResult, len(scripts))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for range min(e.workers, len(scripts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				results[i] = e.execute(scripts[i])
+			}
+		}()
+	}
+
+	for i := range scripts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, ScriptError{Name: result.Name, Err: result.Err})
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (e *Executor) execute(script Script) ScriptResult {
+	output := bytes.NewBuffer([]byte(""))
+
+	opts := append(e.opts[:len(e.opts):len(e.opts)], WithStdout(output))
+	err := NewLox(opts...).Execute(script.Source)
+
+	return ScriptResult{
+		Name:   script.Name,
+		Output: output.String(),
+		Err:    err,
+	}
+}
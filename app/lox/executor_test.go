@@ -0,0 +1,137 @@
+package lox_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+)
+
+// These tests are meant to be run with the race detector: go test -race ./...
+
+func TestExecutor(t *testing.T) {
+	var scripts []lox.Script
+	for i := range 200 {
+		scripts = append(scripts, lox.Script{
+			Name:   fmt.Sprintf("script-%v", i),
+			Source: strings.NewReader(fmt.Sprintf("print %v * 2;\nprint \"done\";", i)),
+		})
+	}
+	scripts = append(scripts, lox.Script{
+		Name:   "runtime-error",
+		Source: strings.NewReader("print \"before\";\n49 + \"baz\";"),
+	})
+	scripts = append(scripts, lox.Script{
+		Name:   "syntax-error",
+		Source: strings.NewReader("print 1"),
+	})
+
+	results, err := lox.NewExecutor(8).Execute(scripts)
+
+	if len(results) != len(scripts) {
+		t.Fatalf("expected %v results, got: %v", len(scripts), len(results))
+	}
+
+	for i := range 200 {
+		expectedOut := fmt.Sprintf("%v\ndone\n", i*2)
+		if results[i].Output != expectedOut {
+			t.Errorf("\nexpected output of %v:\n%q\ngot:\n%q\n", results[i].Name, expectedOut, results[i].Output)
+		}
+
+		if results[i].Err != nil {
+			t.Errorf("did not expect error in %v, but got: %v", results[i].Name, results[i].Err)
+		}
+	}
+
+	if results[200].Output != "before\n" {
+		t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", "before\n", results[200].Output)
+	}
+
+	if !errors.As(err, &lox.RuntimeError{}) {
+		t.Errorf("expected runtime error, got: %v", err)
+	}
+
+	if !errors.As(err, &lox.SyntaxError{}) {
+		t.Errorf("expected syntax error, got: %v", err)
+	}
+
+	expectedErr := "runtime-error: Operands must be two numbers or two strings.\n[line 2]\nsyntax-error: [line 1] Expect ';' after value."
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("\nexpected error:\n%q\ngot:\n%q\n", expectedErr, err)
+	}
+}
+
+func TestConcurrentInstances(t *testing.T) {
+	input := "(54 - 67) >= -(114 / 57 + 11) != (\"foo\" == \"bar\")"
+	// The program uses the natives, the lists, the maps and the loops, which all read the shared lookup tables.
+	program := "for (k, v in {a: [1, 2].push(3), b: {1: bigint(4)}}) print v;\n" +
+		"for (i, c in \"ab\") { print c; break; }\n" +
+		"print [10, 20, 30][-1] + int(2.5) ** 2;\n" +
+		"print decimal(\"1.10\") * 3;\n"
+	expectedOut := "[1, 2, 3]\n{1: 4}\na\n34\n3.30\n"
+
+	tables := lox.LookupTables()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			l := lox.NewLox()
+
+			result, err := l.Tokenize(strings.NewReader(input))
+			if err != nil || len(result.Errors) > 0 {
+				t.Errorf("unexpected tokenize errors: %v %v", err, result.Errors)
+			}
+
+			expr, err := l.ParseExpression(strings.NewReader(input))
+			if err != nil {
+				t.Errorf("did not expect error, but got: %v", err)
+				return
+			}
+
+			_, err = lox.FormatExpression(expr)
+			if err != nil {
+				t.Errorf("did not expect error, but got: %v", err)
+			}
+
+			out, err := l.Evaluate(strings.NewReader(input))
+			if err != nil {
+				t.Errorf("did not expect error, but got: %v", err)
+			}
+
+			if out != true {
+				t.Errorf("expected output: true, got: %v", out)
+			}
+
+			_, err = l.FormatSource(strings.NewReader(program))
+			if err != nil {
+				t.Errorf("did not expect error, but got: %v", err)
+			}
+
+			for _, run := range []func(*lox.Lox) error{
+				func(l *lox.Lox) error { return l.Execute(strings.NewReader(program)) },
+				func(l *lox.Lox) error { return l.RunStream(strings.NewReader(program)) },
+			} {
+				stdout := &strings.Builder{}
+				err := run(lox.NewLox(lox.WithStdout(stdout)))
+				if err != nil {
+					t.Errorf("did not expect error, but got: %v", err)
+				}
+
+				if stdout.String() != expectedOut {
+					t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", expectedOut, stdout.String())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if lox.LookupTables() != tables {
+		t.Errorf("the lookup tables were modified while running the programs")
+	}
+}
@@ -0,0 +1,9 @@
+package lox
+
+import "fmt"
+
+// LookupTables prints the package-level lookup tables, so the tests can check that running programs does not modify them.
+// The maps are printed with their keys sorted, and the native functions with their addresses.
+func LookupTables() string {
+	return fmt.Sprint(tokenLexemes, keywords, natives, compoundOperators)
+}
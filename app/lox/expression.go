@@ -9,13 +9,26 @@ type expressionVisitor interface {
 	visitGroupingExpression(expr *groupingExpression) (any, error)
 	visitLiteralExpression(expr *literalExpression) (any, error)
 	visitUnaryExpression(expr *unaryExpression) (any, error)
+	visitInterpolationExpression(expr *interpolationExpression) (any, error)
+	visitVariableExpression(expr *variableExpression) (any, error)
+	visitCallExpression(expr *callExpression) (any, error)
+	visitCompoundAssignExpression(expr *compoundAssignExpression) (any, error)
+	visitUpdateExpression(expr *updateExpression) (any, error)
+	visitConditionalExpression(expr *conditionalExpression) (any, error)
+	visitCoalesceExpression(expr *coalesceExpression) (any, error)
+	visitGetExpression(expr *getExpression) (any, error)
+	visitIndexExpression(expr *indexExpression) (any, error)
+	visitOptionalChainExpression(expr *optionalChainExpression) (any, error)
+	visitListExpression(expr *listExpression) (any, error)
+	visitAssignExpression(expr *assignExpression) (any, error)
+	visitMapExpression(expr *mapExpression) (any, error)
 }
 
 // Example: 2+3
 type binaryExpression struct {
 	Left     Expression
 	Right    Expression
-	Operator token
+	Operator Token
 }
 
 func (b *binaryExpression) accept(visitor expressionVisitor) (any, error) {
@@ -25,6 +38,8 @@ func (b *binaryExpression) accept(visitor expressionVisitor) (any, error) {
 // Example: (<EXPR>)
 type groupingExpression struct {
 	Expression Expression
+	LeftParen  Token
+	RightParen Token
 }
 
 func (g *groupingExpression) accept(visitor expressionVisitor) (any, error) {
@@ -34,6 +49,8 @@ func (g *groupingExpression) accept(visitor expressionVisitor) (any, error) {
 // Example: 3
 type literalExpression struct {
 	Value any
+	// The token the value comes from. For the string parts of an interpolation, it is the whole INTERPOLATION or STRING token.
+	Token Token
 }
 
 func (l *literalExpression) accept(visitor expressionVisitor) (any, error) {
@@ -43,9 +60,155 @@ func (l *literalExpression) accept(visitor expressionVisitor) (any, error) {
 // Example: -x
 type unaryExpression struct {
 	Right    Expression
-	Operator token
+	Operator Token
 }
 
 func (u *unaryExpression) accept(visitor expressionVisitor) (any, error) {
 	return visitor.visitUnaryExpression(u)
 }
+
+// Example: "Hello ${name}!"
+// The string parts are literal expressions, the rest are the interpolated expressions.
+type interpolationExpression struct {
+	Parts []Expression
+}
+
+func (i *interpolationExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitInterpolationExpression(i)
+}
+
+// Example: decimal
+type variableExpression struct {
+	Name Token
+}
+
+func (v *variableExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitVariableExpression(v)
+}
+
+// Example: decimal("1.10")
+type callExpression struct {
+	Callee    Expression
+	Arguments []Expression
+	// The closing paren, its line is reported in the runtime errors of the call.
+	Paren Token
+}
+
+func (c *callExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitCallExpression(c)
+}
+
+// Example: total += price
+type compoundAssignExpression struct {
+	Target   Expression
+	Operator Token
+	Value    Expression
+}
+
+func (c *compoundAssignExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitCompoundAssignExpression(c)
+}
+
+// Example: count++ or --count
+type updateExpression struct {
+	Target   Expression
+	Operator Token
+	// Whether the operator comes before the target. The prefix form evaluates to the new value, the postfix form to the old one.
+	Prefix bool
+}
+
+func (u *updateExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitUpdateExpression(u)
+}
+
+// Example: count > 0 ? "some" : "none"
+type conditionalExpression struct {
+	Condition Expression
+	Then      Expression
+	Else      Expression
+}
+
+func (c *conditionalExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitConditionalExpression(c)
+}
+
+// Example: name ?? "anonymous"
+type coalesceExpression struct {
+	Left     Expression
+	Right    Expression
+	Operator Token
+}
+
+func (c *coalesceExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitCoalesceExpression(c)
+}
+
+// Example: config.port or config?.port
+type getExpression struct {
+	Object Expression
+	Name   Token
+	// Whether it is accessed with `?.`, which short-circuits the chain when the object is nil.
+	Optional bool
+}
+
+func (g *getExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitGetExpression(g)
+}
+
+// Example: items?.[0]
+type indexExpression struct {
+	Object Expression
+	Index  Expression
+	// The closing bracket, its line is reported in the runtime errors.
+	Bracket  Token
+	Optional bool
+}
+
+func (i *indexExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitIndexExpression(i)
+}
+
+// Example: config?.server.port
+// The whole chain evaluates to nil as soon as one of its optional links is applied to nil.
+type optionalChainExpression struct {
+	Expression Expression
+}
+
+func (o *optionalChainExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitOptionalChainExpression(o)
+}
+
+// Example: [1, 2, 3]
+type listExpression struct {
+	Elements     []Expression
+	LeftBracket  Token
+	RightBracket Token
+}
+
+func (l *listExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitListExpression(l)
+}
+
+// Example: items[0] = 1
+type assignExpression struct {
+	Target Expression
+	Equals Token
+	Value  Expression
+}
+
+func (a *assignExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitAssignExpression(a)
+}
+
+// Example: {"a": 1, b: 2}
+// A bare identifier key is a string literal expression that keeps the IDENTIFIER token, so it can be formatted back the same way.
+type mapExpression struct {
+	Keys       []Expression
+	Values     []Expression
+	LeftBrace  Token
+	RightBrace Token
+}
+
+func (m *mapExpression) accept(visitor expressionVisitor) (any, error) {
+	return visitor.visitMapExpression(m)
+}
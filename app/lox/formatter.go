@@ -0,0 +1,453 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sourcePrinter re-emits the Lox source code from the syntax tree.
+// Unlike the `printer`, the output is valid Lox code.
+type sourcePrinter struct {
+	// How many blocks the printed statement is nested in, every level is indented with two spaces.
+	depth int
+	// The first and the last token of every statement, their trivia has the comments around the statement.
+	bounds map[Statement][2]Token
+	// The comments that have been printed, by the position of the token they are attached to.
+	printed map[commentPosition]bool
+}
+
+type commentPosition struct {
+	line, column int
+	trailing     bool
+}
+
+// FormatSource formats the Lox source code with canonical spacing, one statement per line.
+// Formatting already formatted code does not change it.
+// The comments before and after the statements are kept, each on its own line or at the end of the statement's line.
+// A comment anywhere else, like in the middle of an expression, is an error, because it could not be kept.
+// The source with lexical errors is not formatted either, the first one is returned as an UnexpectedTokenError.
+func (l *Lox) FormatSource(r io.Reader) (string, error) {
+	withTrivia := *l
+	withTrivia.trivia = true
+	result, err := withTrivia.Tokenize(r)
+	if err != nil {
+		return "", err
+	}
+
+	// The tokens that could not be scanned are missing from the result, so formatting it would drop them from the source.
+	if len(result.Errors) > 0 {
+		return "", result.Errors[0]
+	}
+
+	parser := newParser(result.Tokens)
+	statements, err := parser.parse()
+	if err != nil {
+		return "", err
+	}
+
+	out := ""
+	printer := &sourcePrinter{bounds: parser.bounds, printed: map[commentPosition]bool{}}
+
+	for _, statement := range statements {
+		formatted, err := printer.format(statement)
+		if err != nil {
+			return "", fmt.Errorf("failed to format source: %w", err)
+		}
+
+		out += fmt.Sprintf("%v\n", formatted)
+	}
+
+	// The comments after the last statement are attached to the EOF token.
+	eof := result.Tokens[len(result.Tokens)-1]
+	for _, comment := range printer.leadingComments(eof) {
+		out += comment + "\n"
+	}
+
+	for _, t := range result.Tokens {
+		if hasComment(t.LeadingTrivia) && !printer.printed[commentPosition{line: t.Line, column: t.Column}] ||
+			hasComment(t.TrailingTrivia) && !printer.printed[commentPosition{line: t.Line, column: t.Column, trailing: true}] {
+			return "", fmt.Errorf("[line %v] Cannot format a comment inside a statement, only the comments between the statements are kept.", t.Line)
+		}
+	}
+
+	return out, nil
+}
+
+func hasComment(trivias []Trivia) bool {
+	for _, tv := range trivias {
+		if tv.Kind == COMMENT {
+			return true
+		}
+	}
+
+	return false
+}
+
+// format prints the statement with the comments before it and the comment at the end of its line.
+// The comments before it are indented the same as the statement, except for the first line, which the caller indents.
+func (p *sourcePrinter) format(statement Statement) (string, error) {
+	out, err := statement.accept(p)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := p.bounds[statement]
+	result := ""
+	for _, comment := range p.leadingComments(bounds[0]) {
+		result += comment + "\n" + strings.Repeat("  ", p.depth)
+	}
+	result += fmt.Sprintf("%v", out)
+	if comment, ok := p.trailingComment(bounds[1]); ok {
+		result += " " + comment
+	}
+
+	return result, nil
+}
+
+// leadingComments returns the comments in the leading trivia of the token and marks them as printed.
+func (p *sourcePrinter) leadingComments(t Token) []string {
+	var comments []string
+	for _, tv := range t.LeadingTrivia {
+		if tv.Kind == COMMENT {
+			comments = append(comments, tv.Text)
+		}
+	}
+
+	p.printed[commentPosition{line: t.Line, column: t.Column}] = true
+	return comments
+}
+
+// trailingComment returns the comment in the trailing trivia of the token, unless it has already been printed.
+// The last token of a loop is also the last token of its body, and the comment is printed after the body.
+func (p *sourcePrinter) trailingComment(t Token) (string, bool) {
+	position := commentPosition{line: t.Line, column: t.Column, trailing: true}
+	if p.printed[position] {
+		return "", false
+	}
+	p.printed[position] = true
+
+	for _, tv := range t.TrailingTrivia {
+		if tv.Kind == COMMENT {
+			return tv.Text, true
+		}
+	}
+
+	return "", false
+}
+
+func (p *sourcePrinter) visitExprStatement(statement *exprStatement) (any, error) {
+	expr, err := statement.expr.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("%v;", expr), nil
+}
+
+func (p *sourcePrinter) visitPrintStatement(statement *printStatement) (any, error) {
+	expr, err := statement.expr.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("print %v;", expr), nil
+}
+
+func (p *sourcePrinter) visitBreakStatement(statement *breakStatement) (any, error) {
+	return "break;", nil
+}
+
+func (p *sourcePrinter) visitContinueStatement(statement *continueStatement) (any, error) {
+	return "continue;", nil
+}
+
+func (p *sourcePrinter) visitBlockStatement(statement *blockStatement) (any, error) {
+	result := "{"
+	if comment, ok := p.trailingComment(statement.leftBrace); ok {
+		result += " " + comment
+	}
+
+	p.depth++
+	indent := strings.Repeat("  ", p.depth)
+	for _, s := range statement.statements {
+		out, err := p.format(s)
+		if err != nil {
+			return nil, err
+		}
+
+		result += fmt.Sprintf("\n%s%v", indent, out)
+	}
+
+	// The comments after the last statement of the block are attached to the closing brace.
+	for _, comment := range p.leadingComments(statement.rightBrace) {
+		result += "\n" + indent + comment
+	}
+	p.depth--
+
+	if result == "{" {
+		return "{}", nil
+	}
+
+	return result + "\n" + strings.Repeat("  ", p.depth) + "}", nil
+}
+
+func (p *sourcePrinter) visitForInStatement(statement *forInStatement) (any, error) {
+	var variables []string
+	for _, variable := range statement.variables {
+		variables = append(variables, *variable.Lexeme)
+	}
+
+	iterable, err := statement.iterable.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	body, err := statement.body.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("for (%s in %v) %v", strings.Join(variables, ", "), iterable, body), nil
+}
+
+func (p *sourcePrinter) visitBinaryExpression(expr *binaryExpression) (any, error) {
+	left, err := expr.Left.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	right, err := expr.Right.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("%v %s %v", left, *expr.Operator.Lexeme, right), nil
+}
+
+func (p *sourcePrinter) visitGroupingExpression(expr *groupingExpression) (any, error) {
+	inner, err := expr.Expression.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("(%v)", inner), nil
+}
+
+// visitLiteralExpression keeps the numbers and the strings the way they were written, like `0x2A`, `1_000` or `"\u{41}"`.
+func (p *sourcePrinter) visitLiteralExpression(expr *literalExpression) (any, error) {
+	if expr.Token.Type == NUMBER || expr.Token.Type == STRING {
+		return *expr.Token.Lexeme, nil
+	}
+
+	if expr.Value == nil {
+		return "nil", nil
+	}
+
+	return fmt.Sprintf("%v", expr.Value), nil
+}
+
+func (p *sourcePrinter) visitInterpolationExpression(expr *interpolationExpression) (any, error) {
+	result := ""
+	for i, part := range expr.Parts {
+		// The parts alternate between the string parts and the interpolated expressions.
+		if i%2 == 0 {
+			result += stringPart(part.(*literalExpression).Token)
+			continue
+		}
+
+		out, err := part.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		result += fmt.Sprintf("${%v}", out)
+	}
+
+	return fmt.Sprintf("\"%s\"", result), nil
+}
+
+func (p *sourcePrinter) visitVariableExpression(expr *variableExpression) (any, error) {
+	return *expr.Name.Lexeme, nil
+}
+
+func (p *sourcePrinter) visitCallExpression(expr *callExpression) (any, error) {
+	callee, err := expr.Callee.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var arguments []string
+	for _, argument := range expr.Arguments {
+		out, err := argument.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = append(arguments, fmt.Sprintf("%v", out))
+	}
+
+	return fmt.Sprintf("%v(%s)", callee, strings.Join(arguments, ", ")), nil
+}
+
+func (p *sourcePrinter) visitCompoundAssignExpression(expr *compoundAssignExpression) (any, error) {
+	target, err := expr.Target.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	value, err := expr.Value.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("%v %s %v", target, *expr.Operator.Lexeme, value), nil
+}
+
+func (p *sourcePrinter) visitUpdateExpression(expr *updateExpression) (any, error) {
+	target, err := expr.Target.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Prefix {
+		return fmt.Sprintf("%s%v", *expr.Operator.Lexeme, target), nil
+	}
+
+	return fmt.Sprintf("%v%s", target, *expr.Operator.Lexeme), nil
+}
+
+func (p *sourcePrinter) visitConditionalExpression(expr *conditionalExpression) (any, error) {
+	condition, err := expr.Condition.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	thenBranch, err := expr.Then.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	elseBranch, err := expr.Else.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("%v ? %v : %v", condition, thenBranch, elseBranch), nil
+}
+
+func (p *sourcePrinter) visitCoalesceExpression(expr *coalesceExpression) (any, error) {
+	left, err := expr.Left.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	right, err := expr.Right.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("%v %s %v", left, *expr.Operator.Lexeme, right), nil
+}
+
+func (p *sourcePrinter) visitGetExpression(expr *getExpression) (any, error) {
+	object, err := expr.Object.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Optional {
+		return fmt.Sprintf("%v?.%s", object, *expr.Name.Lexeme), nil
+	}
+
+	return fmt.Sprintf("%v.%s", object, *expr.Name.Lexeme), nil
+}
+
+func (p *sourcePrinter) visitIndexExpression(expr *indexExpression) (any, error) {
+	object, err := expr.Object.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	index, err := expr.Index.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Optional {
+		return fmt.Sprintf("%v?.[%v]", object, index), nil
+	}
+
+	return fmt.Sprintf("%v[%v]", object, index), nil
+}
+
+func (p *sourcePrinter) visitListExpression(expr *listExpression) (any, error) {
+	var elements []string
+	for _, element := range expr.Elements {
+		out, err := element.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, fmt.Sprintf("%v", out))
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(elements, ", ")), nil
+}
+
+func (p *sourcePrinter) visitMapExpression(expr *mapExpression) (any, error) {
+	var entries []string
+	for i := range expr.Keys {
+		key, err := expr.Keys[i].accept(p)
+		if err != nil {
+			return nil, err
+		}
+		// The bare identifier keys are kept bare.
+		if literal, ok := expr.Keys[i].(*literalExpression); ok && literal.Token.Type == IDENTIFIER {
+			key = *literal.Token.Lexeme
+		}
+
+		value, err := expr.Values[i].accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, fmt.Sprintf("%v: %v", key, value))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(entries, ", ")), nil
+}
+
+func (p *sourcePrinter) visitAssignExpression(expr *assignExpression) (any, error) {
+	target, err := expr.Target.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	value, err := expr.Value.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("%v = %v", target, value), nil
+}
+
+func (p *sourcePrinter) visitOptionalChainExpression(expr *optionalChainExpression) (any, error) {
+	return expr.Expression.accept(p)
+}
+
+func (p *sourcePrinter) visitUnaryExpression(expr *unaryExpression) (any, error) {
+	right, err := expr.Right.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// `- -1` must not become `--1`, which is the decrement operator.
+	if *expr.Operator.Lexeme == tokenLexemes[MINUS] && strings.HasPrefix(fmt.Sprintf("%v", right), tokenLexemes[MINUS]) {
+		return fmt.Sprintf("%s %v", *expr.Operator.Lexeme, right), nil
+	}
+
+	return fmt.Sprintf("%s%v", *expr.Operator.Lexeme, right), nil
+}
+
+// stringPart returns the source of the string part of the interpolated string, without the quote, the `${` and the `}` around it.
+func stringPart(t Token) string {
+	// The first part starts with the quote, the others with the `}`.
+	part := (*t.Lexeme)[1:]
+	if t.Type == INTERPOLATION {
+		return part[:len(part)-len("${")]
+	}
+
+	return part[:len(part)-1]
+}
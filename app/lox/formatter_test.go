@@ -0,0 +1,183 @@
+package lox_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+)
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedOut string
+		expectedErr string
+	}{
+		{
+			input:       "print   1+2 ;",
+			expectedOut: "print 1 + 2;\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print \"foo\";print\"bar\";",
+			expectedOut: "print \"foo\";\nprint \"bar\";\n",
+			expectedErr: "",
+		},
+		{
+			input:       "27-60>=-99*2/99+76;\ntrue==true;\n\n\n(\"world\"==\"bar\")==(\"baz\"!=\"hello\");",
+			expectedOut: "27 - 60 >= -99 * 2 / 99 + 76;\ntrue == true;\n(\"world\" == \"bar\") == (\"baz\" != \"hello\");\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print [ 1,2, ][0];items [ 1 ]=[];",
+			expectedOut: "print [1, 2][0];\nitems[1] = [];\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print {a:1,\"b\" :[ ],};{x:1}[\"x\"]=2;",
+			expectedOut: "print {a: 1, \"b\": []};\n{x: 1}[\"x\"] = 2;\n",
+			expectedErr: "",
+		},
+		{
+			input:       "for(k,v in {a:1}){print k;{}for(c in v)print c;}",
+			expectedOut: "for (k, v in {a: 1}) {\n  print k;\n  {}\n  for (c in v) print c;\n}\n",
+			expectedErr: "",
+		},
+		{
+			input:       "// header\n/* block\n comment */\nprint   1;// one\nfor(x in [1]){ // open\n// inside\nprint x; // two\n  // last\n}\n{\n// only\n}\n// trailer\n",
+			expectedOut: "// header\n/* block\n comment */\nprint 1; // one\nfor (x in [1]) { // open\n  // inside\n  print x; // two\n  // last\n}\n{\n  // only\n}\n// trailer\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print \"a\\n\";print \"\\\"${ x }\\u{41}\\\"\";",
+			expectedOut: "print \"a\\n\";\nprint \"\\\"${x}\\u{41}\\\"\";\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print 1_000+0b1010 *1.5e-3d;",
+			expectedOut: "print 1_000 + 0b1010 * 1.5e-3d;\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print 1 + // inside\n2;",
+			expectedOut: "",
+			expectedErr: "[line 1] Cannot format a comment inside a statement, only the comments between the statements are kept.",
+		},
+		{
+			input:       "for (x in [1]) /* body */ print x;",
+			expectedOut: "",
+			expectedErr: "[line 1] Cannot format a comment inside a statement, only the comments between the statements are kept.",
+		},
+		{
+			input:       "print 10.40; print 42.0; print 42; print 0x2A; print 1e3; print !!nil;",
+			expectedOut: "print 10.40;\nprint 42.0;\nprint 42;\nprint 0x2A;\nprint 1e3;\nprint !!nil;\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print (( 1 ) );",
+			expectedOut: "print ((1));\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print \"multi\nline\"; // comment",
+			expectedOut: "print \"multi\nline\"; // comment\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print \"tab\\t \\\"quote\\\" \\\\ \\u{41}\\n\";",
+			expectedOut: "print \"tab\\t \\\"quote\\\" \\\\ \\u{41}\\n\";\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print \"a ${ 1+2 } \\${b} ${\"c${ true }\"}\";",
+			expectedOut: "print \"a ${1 + 2} \\${b} ${\"c${true}\"}\";\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print 2**-1&~3;7~/2%3;",
+			expectedOut: "print 2 ** -1 & ~3;\n7 ~/ 2 % 3;\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print decimal( \"1.10\" )*1.10d+0xFFn;int();",
+			expectedOut: "print decimal(\"1.10\") * 1.10d + 0xFFn;\nint();\n",
+			expectedErr: "",
+		},
+		{
+			input:       "total+=price*2;count ++;-- count;print - -1;",
+			expectedOut: "total += price * 2;\ncount++;\n--count;\nprint - -1;\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print a?b:c??d;",
+			expectedOut: "print a ? b : c ?? d;\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print config ?. server.port( ) ?? items?.[ 0 ];",
+			expectedOut: "print config?.server.port() ?? items?.[0];\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print 1",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect ';' after value.",
+		},
+		{
+			input:       "print 1; @ print 2;",
+			expectedOut: "",
+			expectedErr: "[line 1] Error: Unexpected character: @\n",
+		},
+		{
+			input:       "print 1;\n\"abc",
+			expectedOut: "",
+			expectedErr: "[line 2] Error: Unterminated string.\n",
+		},
+		{
+			input:       "print 1;\n/* open",
+			expectedOut: "",
+			expectedErr: "[line 2] Error: Unterminated block comment.\n",
+		},
+		{
+			input:       "print 1 + # 2;",
+			expectedOut: "",
+			expectedErr: "[line 1] Error: Unexpected character: #\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := lox.NewLox()
+
+			out, err := l.FormatSource(strings.NewReader(tt.input))
+			if err != nil && tt.expectedErr == "" {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			if err != nil && tt.expectedErr != err.Error() {
+				t.Errorf("\nexpected error:\n%q\ngot:\n%q\n", tt.expectedErr, err.Error())
+			}
+
+			if err == nil && tt.expectedErr != "" {
+				t.Errorf("expected error:\n%q\nreceived: none\n", tt.expectedErr)
+			}
+
+			if out != tt.expectedOut {
+				t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", tt.expectedOut, out)
+			}
+
+			if err != nil {
+				return
+			}
+
+			again, err := l.FormatSource(strings.NewReader(out))
+			if err != nil {
+				t.Fatalf("did not expect error when formatting the output, but got: %v", err)
+			}
+
+			if again != out {
+				t.Errorf("\nformatting is not idempotent, expected:\n%q\ngot:\n%q\n", out, again)
+			}
+		})
+	}
+}
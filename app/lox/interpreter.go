@@ -1,8 +1,10 @@
 package lox
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math/big"
 )
 
 type RuntimeError struct {
@@ -20,33 +22,85 @@ func (l *Lox) Evaluate(r io.Reader) (any, error) {
 		return nil, err
 	}
 
-	return expr.accept(&evaluator{})
+	return expr.accept(newEvaluator(l.stdout))
 }
 
+// Execute executes the program read from the input.
+// The output of the `print` statements is written to the stdout of the Lox instance.
+func (l *Lox) Execute(input io.Reader) error {
+	return l.Run(input, l.stdout)
+}
+
+// Run executes the program read from the input, the output of the `print` statements is written to the output.
+// It predates the configurable stdout and is kept for the existing callers, Execute uses the stdout of the Lox instance.
 func (l *Lox) Run(input io.Reader, output io.Writer) error {
 	statements, err := l.Parse(input)
 	if err != nil {
 		return err
 	}
 
+	evaluator := newEvaluator(output)
 	for _, statement := range statements {
-		out, err := statement.accept(&evaluator{})
+		_, err := statement.accept(evaluator)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunStream executes the program read from the input one top-level statement at a time.
+// Every statement runs as soon as it has been read in full, so it does not wait for the rest of the input.
+// If the stdout of the Lox instance can be flushed, it is flushed after every statement.
+func (l *Lox) RunStream(input io.Reader) error {
+	scanner := NewScanner(input)
+	evaluator := newEvaluator(l.stdout)
+
+	for {
+		tokens, err := nextStatementTokens(scanner)
 		if err != nil {
 			return err
 		}
 
-		if out != nil {
-			_, err = output.Write([]byte(fmt.Sprintf("%v\n", out)))
+		statements, err := newParser(tokens).parse()
+		if err != nil {
+			return err
+		}
+
+		for _, statement := range statements {
+			_, err := statement.accept(evaluator)
+			if err != nil {
+				return err
+			}
+		}
+
+		if f, ok := l.stdout.(flusher); ok {
+			err := f.Flush()
 			if err != nil {
-				return fmt.Errorf("failed to write to output: %w", err)
+				return fmt.Errorf("failed to flush stdout: %w", err)
 			}
 		}
+
+		if len(statements) == 0 {
+			return nil
+		}
 	}
+}
 
-	return nil
+type flusher interface {
+	Flush() error
+}
+
+type evaluator struct {
+	stdout io.Writer
+	// The innermost scope, it is nil outside of the blocks and the loops.
+	environment *environment
 }
 
-type evaluator struct{}
+func newEvaluator(stdout io.Writer) *evaluator {
+	return &evaluator{stdout: stdout}
+}
 
 func (e *evaluator) visitPrintStatement(statement *printStatement) (any, error) {
 	out, err := statement.expr.accept(e)
@@ -54,7 +108,12 @@ func (e *evaluator) visitPrintStatement(statement *printStatement) (any, error)
 		return nil, err
 	}
 
-	return fmt.Sprintf("%v", out), nil
+	_, err = fmt.Fprintf(e.stdout, "%s\n", stringify(out))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write to stdout: %w", err)
+	}
+
+	return nil, nil
 }
 
 func (e *evaluator) visitExprStatement(statement *exprStatement) (any, error) {
@@ -66,119 +125,117 @@ func (e *evaluator) visitExprStatement(statement *exprStatement) (any, error) {
 	return nil, nil
 }
 
-func (e *evaluator) visitBinaryExpression(expr *binaryExpression) (any, error) {
-	left, err := expr.Left.accept(e)
-	if err != nil {
-		return nil, err
+// errBreak and errContinue unwind the statements up to the innermost loop, which is where they are handled.
+// The parser only accepts `break` and `continue` inside loops, so they never escape the loop.
+var (
+	errBreak    = errors.New("break outside of a loop")
+	errContinue = errors.New("continue outside of a loop")
+)
+
+func (e *evaluator) visitBreakStatement(statement *breakStatement) (any, error) {
+	return nil, errBreak
+}
+
+func (e *evaluator) visitContinueStatement(statement *continueStatement) (any, error) {
+	return nil, errContinue
+}
+
+func (e *evaluator) visitBlockStatement(statement *blockStatement) (any, error) {
+	enclosing := e.environment
+	e.environment = newEnvironment(enclosing)
+	defer func() { e.environment = enclosing }()
+
+	for _, s := range statement.statements {
+		_, err := s.accept(e)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	right, err := expr.Right.accept(e)
+	return nil, nil
+}
+
+// visitForInStatement runs the body once for every item, each time in a new scope with the loop variables.
+// With a single variable, a map gives its keys and the lists and the strings give their elements.
+func (e *evaluator) visitForInStatement(statement *forInStatement) (any, error) {
+	iterable, err := statement.iterable.accept(e)
 	if err != nil {
 		return nil, err
 	}
 
-	switch *expr.Operator.Lexeme {
-	case tokenLexemes[MINUS]:
-		{
-			lv, err := toF64(left)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
-			rv, err := toF64(right)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
+	it, ok := iteratorOf(iterable)
+	if !ok {
+		return nil, RuntimeError{line: statement.in.Line, message: "Can only iterate over lists, maps and strings."}
+	}
+	_, isMap := iterable.(*dictionary)
 
-			return lv - rv, nil
-		}
-	case tokenLexemes[PLUS]:
-		{
-			sum, err := add(left, right)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers or two strings."}
-			}
+	enclosing := e.environment
+	defer func() { e.environment = enclosing }()
 
-			return sum, nil
+	for {
+		key, value, ok := it.next()
+		if !ok {
+			return nil, nil
 		}
-	case tokenLexemes[STAR]:
-		{
-			lv, err := toF64(left)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
-			rv, err := toF64(right)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
 
-			return lv * rv, nil
+		e.environment = newEnvironment(enclosing)
+		switch {
+		case len(statement.variables) == 2:
+			e.environment.define(*statement.variables[0].Lexeme, key)
+			e.environment.define(*statement.variables[1].Lexeme, value)
+		case isMap:
+			e.environment.define(*statement.variables[0].Lexeme, key)
+		default:
+			e.environment.define(*statement.variables[0].Lexeme, value)
 		}
-	case tokenLexemes[SLASH]:
-		{
-			lv, err := toF64(left)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
-			rv, err := toF64(right)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
 
-			return lv / rv, nil
+		_, err := statement.body.accept(e)
+		if errors.Is(err, errBreak) {
+			return nil, nil
 		}
-	case tokenLexemes[GREATER]:
-		{
-			lv, err := toF64(left)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
-			rv, err := toF64(right)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
+		if err != nil && !errors.Is(err, errContinue) {
+			return nil, err
+		}
+	}
+}
 
-			return lv > rv, nil
+func (e *evaluator) visitBinaryExpression(expr *binaryExpression) (any, error) {
+	left, err := expr.Left.accept(e)
+	if err != nil {
+		return nil, err
+	}
 
-		}
-	case tokenLexemes[GREATER_EQUAL]:
-		{
-			lv, err := toF64(left)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
-			rv, err := toF64(right)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
+	right, err := expr.Right.accept(e)
+	if err != nil {
+		return nil, err
+	}
 
-			return lv >= rv, nil
+	return binaryOperation(expr.Operator, left, right)
+}
+
+// binaryOperation applies the binary operator to the already evaluated operands.
+func binaryOperation(operator Token, left, right any) (any, error) {
+	switch *operator.Lexeme {
+	case tokenLexemes[MINUS], tokenLexemes[STAR], tokenLexemes[SLASH], tokenLexemes[PERCENT], tokenLexemes[STAR_STAR], tokenLexemes[TILDE_SLASH]:
+		{
+			return arithmetic(operator, left, right)
 		}
-	case tokenLexemes[LESS]:
+	case tokenLexemes[PLUS]:
 		{
-			lv, err := toF64(left)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
+			if isNumber(left) && isNumber(right) {
+				return arithmetic(operator, left, right)
 			}
-			rv, err := toF64(right)
+
+			sum, err := add(left, right)
 			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
+				return nil, RuntimeError{line: operator.Line, message: "Operands must be two numbers or two strings."}
 			}
 
-			return rv < lv, nil
-
+			return sum, nil
 		}
-	case tokenLexemes[LESS_EQUAL]:
+	case tokenLexemes[GREATER], tokenLexemes[GREATER_EQUAL], tokenLexemes[LESS], tokenLexemes[LESS_EQUAL]:
 		{
-			lv, err := toF64(left)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
-			rv, err := toF64(right)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operands must be two numbers."}
-			}
-
-			return lv <= rv, nil
+			return comparison(operator, left, right)
 		}
 	case tokenLexemes[EQUAL_EQUAL]:
 		{
@@ -200,6 +257,10 @@ func (e *evaluator) visitBinaryExpression(expr *binaryExpression) (any, error) {
 
 			return !result, nil
 		}
+	case tokenLexemes[AMPERSAND], tokenLexemes[PIPE], tokenLexemes[CARET], tokenLexemes[LESS_LESS], tokenLexemes[GREATER_GREATER]:
+		{
+			return bitwise(operator, left, right)
+		}
 	default:
 		{
 			panic(fmt.Errorf("unknown operator for binary operation"))
@@ -215,6 +276,398 @@ func (e *evaluator) visitLiteralExpression(expr *literalExpression) (any, error)
 	return expr.Value, nil
 }
 
+func (e *evaluator) visitInterpolationExpression(expr *interpolationExpression) (any, error) {
+	result := ""
+	for _, part := range expr.Parts {
+		value, err := part.accept(e)
+		if err != nil {
+			return nil, err
+		}
+
+		result += stringify(value)
+	}
+
+	return result, nil
+}
+
+func (e *evaluator) visitVariableExpression(expr *variableExpression) (any, error) {
+	if value, found := e.environment.get(*expr.Name.Lexeme); found {
+		return value, nil
+	}
+
+	native, found := natives[*expr.Name.Lexeme]
+	if !found {
+		return nil, RuntimeError{line: expr.Name.Line, message: fmt.Sprintf("Undefined variable '%s'.", *expr.Name.Lexeme)}
+	}
+
+	return native, nil
+}
+
+func (e *evaluator) visitCallExpression(expr *callExpression) (any, error) {
+	callee, err := expr.Callee.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var arguments []any
+	for _, argument := range expr.Arguments {
+		value, err := argument.accept(e)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = append(arguments, value)
+	}
+
+	function, ok := callee.(callable)
+	if !ok {
+		return nil, RuntimeError{line: expr.Paren.Line, message: "Can only call functions and classes."}
+	}
+
+	if len(arguments) != function.arity() {
+		return nil, RuntimeError{line: expr.Paren.Line, message: fmt.Sprintf("Expected %v arguments but got %v.", function.arity(), len(arguments))}
+	}
+
+	result, err := function.call(arguments)
+	if err != nil {
+		// The error of a callback, like the function given to `map`, is already reported at its own line.
+		var runtimeError RuntimeError
+		if errors.As(err, &runtimeError) {
+			return nil, err
+		}
+
+		return nil, RuntimeError{line: expr.Paren.Line, message: err.Error()}
+	}
+
+	return result, nil
+}
+
+func (e *evaluator) visitConditionalExpression(expr *conditionalExpression) (any, error) {
+	condition, err := expr.Condition.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTruthy(condition) {
+		return expr.Then.accept(e)
+	}
+
+	return expr.Else.accept(e)
+}
+
+// visitCoalesceExpression only evaluates the right side when the left side is nil, unlike `or`, false is kept.
+func (e *evaluator) visitCoalesceExpression(expr *coalesceExpression) (any, error) {
+	left, err := expr.Left.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if left != nil {
+		return left, nil
+	}
+
+	return expr.Right.accept(e)
+}
+
+// errShortCircuit unwinds the optional chain up to its optionalChainExpression, when an optional link is applied to nil.
+var errShortCircuit = errors.New("optional chain short-circuited")
+
+func (e *evaluator) visitOptionalChainExpression(expr *optionalChainExpression) (any, error) {
+	value, err := expr.Expression.accept(e)
+	if errors.Is(err, errShortCircuit) {
+		return nil, nil
+	}
+
+	return value, err
+}
+
+func (e *evaluator) visitGetExpression(expr *getExpression) (any, error) {
+	object, err := expr.Object.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Optional && object == nil {
+		return nil, errShortCircuit
+	}
+
+	return property(object, expr.Name)
+}
+
+// property returns the named property of the object, the lists and the maps only have their native methods.
+func property(object any, name Token) (any, error) {
+	var method *nativeFunction
+	found := false
+	switch object := object.(type) {
+	case *list:
+		method, found = object.method(*name.Lexeme)
+	case *dictionary:
+		method, found = object.method(*name.Lexeme)
+	default:
+		return nil, RuntimeError{line: name.Line, message: "Only instances have properties."}
+	}
+
+	if !found {
+		return nil, RuntimeError{line: name.Line, message: fmt.Sprintf("Undefined property '%s'.", *name.Lexeme)}
+	}
+
+	return method, nil
+}
+
+func (e *evaluator) visitIndexExpression(expr *indexExpression) (any, error) {
+	object, err := expr.Object.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Optional && object == nil {
+		return nil, errShortCircuit
+	}
+
+	index, err := expr.Index.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return element(object, index, expr.Bracket)
+}
+
+// element returns the element of the list or the value of the map at the index.
+func element(object, index any, bracket Token) (any, error) {
+	var value any
+	var err error
+	switch object := object.(type) {
+	case *list:
+		var i int
+		i, err = object.position(index)
+		if err == nil {
+			value = object.elements[i]
+		}
+	case *dictionary:
+		value, err = object.get(index)
+	default:
+		return nil, RuntimeError{line: bracket.Line, message: "Only lists and maps can be indexed."}
+	}
+
+	if err != nil {
+		return nil, RuntimeError{line: bracket.Line, message: err.Error()}
+	}
+
+	return value, nil
+}
+
+// setElement stores the value into the list or the map at the index.
+func setElement(object, index, value any, bracket Token) error {
+	var err error
+	switch object := object.(type) {
+	case *list:
+		var i int
+		i, err = object.position(index)
+		if err == nil {
+			object.elements[i] = value
+		}
+	case *dictionary:
+		err = object.set(index, value)
+	default:
+		return RuntimeError{line: bracket.Line, message: "Only lists and maps can be indexed."}
+	}
+
+	if err != nil {
+		return RuntimeError{line: bracket.Line, message: err.Error()}
+	}
+
+	return nil
+}
+
+func (e *evaluator) visitListExpression(expr *listExpression) (any, error) {
+	l := &list{elements: make([]any, 0, len(expr.Elements))}
+	for _, element := range expr.Elements {
+		value, err := element.accept(e)
+		if err != nil {
+			return nil, err
+		}
+
+		l.elements = append(l.elements, value)
+	}
+
+	return l, nil
+}
+
+// visitMapExpression evaluates the entries from left to right, a repeated key keeps its first position and its last value.
+func (e *evaluator) visitMapExpression(expr *mapExpression) (any, error) {
+	d := newDictionary()
+	for i := range expr.Keys {
+		key, err := expr.Keys[i].accept(e)
+		if err != nil {
+			return nil, err
+		}
+		value, err := expr.Values[i].accept(e)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.set(key, value)
+		if err != nil {
+			return nil, RuntimeError{line: expr.LeftBrace.Line, message: err.Error()}
+		}
+	}
+
+	return d, nil
+}
+
+// visitAssignExpression evaluates from left to right, the object and the index of the target come before the value.
+func (e *evaluator) visitAssignExpression(expr *assignExpression) (any, error) {
+	target, err := e.resolve(expr.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := expr.Value.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := target.set(value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// compoundOperators maps the compound assignment operators to the binary operators they apply.
+var compoundOperators = map[TokenType]TokenType{
+	PLUS_EQUAL:  PLUS,
+	MINUS_EQUAL: MINUS,
+	STAR_EQUAL:  STAR,
+	SLASH_EQUAL: SLASH,
+}
+
+func (e *evaluator) visitCompoundAssignExpression(expr *compoundAssignExpression) (any, error) {
+	target, err := e.resolve(expr.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	// The target is read before the value is evaluated, same as in `count = count + value`.
+	current, err := target.get()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := expr.Value.accept(e)
+	if err != nil {
+		return nil, err
+	}
+
+	operator := newToken(compoundOperators[expr.Operator.Type], expr.Operator.Line)
+	result, err := binaryOperation(operator, current, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, target.set(result)
+}
+
+func (e *evaluator) visitUpdateExpression(expr *updateExpression) (any, error) {
+	target, err := e.resolve(expr.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := target.get()
+	if err != nil {
+		return nil, err
+	}
+
+	if !isNumber(current) {
+		return nil, RuntimeError{line: expr.Operator.Line, message: "Operand must be a number."}
+	}
+
+	operator := newToken(PLUS, expr.Operator.Line)
+	if expr.Operator.Type == MINUS_MINUS {
+		operator = newToken(MINUS, expr.Operator.Line)
+	}
+
+	result, err := arithmetic(operator, current, int64(1))
+	if err != nil {
+		return nil, err
+	}
+
+	err = target.set(result)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Prefix {
+		return result, nil
+	}
+	return current, nil
+}
+
+// location is a resolved assignment target, it can be read and written without evaluating the target again.
+// So the side effects of the object and the index of `items[next()] += 1` only run once, and the read and the write use the same slot.
+type location struct {
+	get func() (any, error)
+	set func(value any) error
+}
+
+// resolve evaluates the object and the index of the target, the parser has already checked that it is a valid target.
+func (e *evaluator) resolve(target Expression) (location, error) {
+	switch target := target.(type) {
+	case *variableExpression:
+		return location{
+			get: func() (any, error) {
+				return e.visitVariableExpression(target)
+			},
+			set: func(value any) error {
+				if e.environment.assign(*target.Name.Lexeme, value) {
+					return nil
+				}
+
+				// The names that no scope has are either the native functions, which can not be reassigned, or undefined.
+				if _, found := natives[*target.Name.Lexeme]; found {
+					return RuntimeError{line: target.Name.Line, message: fmt.Sprintf("Cannot assign to '%s'.", *target.Name.Lexeme)}
+				}
+				return RuntimeError{line: target.Name.Line, message: fmt.Sprintf("Undefined variable '%s'.", *target.Name.Lexeme)}
+			},
+		}, nil
+	case *getExpression:
+		object, err := target.Object.accept(e)
+		if err != nil {
+			return location{}, err
+		}
+
+		return location{
+			get: func() (any, error) {
+				return property(object, target.Name)
+			},
+			set: func(value any) error {
+				return RuntimeError{line: target.Name.Line, message: "Only instances have fields."}
+			},
+		}, nil
+	case *indexExpression:
+		object, err := target.Object.accept(e)
+		if err != nil {
+			return location{}, err
+		}
+		index, err := target.Index.accept(e)
+		if err != nil {
+			return location{}, err
+		}
+
+		return location{
+			get: func() (any, error) {
+				return element(object, index, target.Bracket)
+			},
+			set: func(value any) error {
+				return setElement(object, index, value, target.Bracket)
+			},
+		}, nil
+	default:
+		panic(fmt.Sprintf("invalid assignment target: %T", target))
+	}
+}
+
 func (e *evaluator) visitUnaryExpression(expr *unaryExpression) (any, error) {
 	value, err := expr.Right.accept(e)
 	if err != nil {
@@ -224,17 +677,16 @@ func (e *evaluator) visitUnaryExpression(expr *unaryExpression) (any, error) {
 	switch *expr.Operator.Lexeme {
 	case tokenLexemes[MINUS]:
 		{
-			v, err := toF64(value)
-			if err != nil {
-				return nil, RuntimeError{line: expr.Operator.Line, message: "Operand must be a number."}
-			}
-
-			return -v, nil
+			return negate(expr.Operator, value)
 		}
 	case tokenLexemes[PLUS]:
 		{
 			return value, nil
 		}
+	case tokenLexemes[TILDE]:
+		{
+			return complement(expr.Operator, value)
+		}
 	case tokenLexemes[BANG]:
 		{
 			return !isTruthy(value), nil
@@ -246,12 +698,45 @@ func (e *evaluator) visitUnaryExpression(expr *unaryExpression) (any, error) {
 	}
 }
 
+// stringify formats the value the same way the `print` statement does.
+func stringify(v any) string {
+	if v == nil {
+		return "nil"
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// stringifyElement stringifies the elements and the keys of the lists and the maps.
+// The strings are quoted, so `["a, b"]` does not print the same as `["a", "b"]`, and `{"1": 1}` not the same as `{1: 1}`.
+func stringifyElement(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return stringify(v)
+}
+
 func toF64(v any) (float64, error) {
 	switch v := v.(type) {
 	case float64:
 		{
 			return v, nil
 		}
+	case int64:
+		{
+			return float64(v), nil
+		}
+	case *big.Int:
+		{
+			f, _ := new(big.Float).SetInt(v).Float64()
+			return f, nil
+		}
+	case decimal:
+		{
+			f, _ := v.rat().Float64()
+			return f, nil
+		}
 	default:
 		{
 			return 0, fmt.Errorf("value %v is not float64", v)
@@ -262,10 +747,6 @@ func toF64(v any) (float64, error) {
 
 func add(left, right any) (any, error) {
 	switch lv := left.(type) {
-	case float64:
-		if rv, ok := right.(float64); ok {
-			return lv + rv, nil
-		}
 	case string:
 		if rv, ok := right.(string); ok {
 			return lv + rv, nil
@@ -284,11 +765,14 @@ func isEqual(left, right any) (bool, error) {
 		return false, nil
 	}
 
+	// The numbers are equal when they have the same value, whatever their type is, so `1 == 1.0` is true.
+	// The keys of the maps are hashed the same way, see keyOf.
+	if isNumber(left) && isNumber(right) {
+		c, ok := compare(left, right)
+		return ok && c == 0, nil
+	}
+
 	switch lv := left.(type) {
-	case float64:
-		if rv, ok := right.(float64); ok {
-			return lv == rv, nil
-		}
 	case string:
 		if rv, ok := right.(string); ok {
 			return lv == rv, nil
@@ -297,6 +781,19 @@ func isEqual(left, right any) (bool, error) {
 		if rv, ok := right.(bool); ok {
 			return lv == rv, nil
 		}
+	case *list:
+		// Same as the instances, two lists are only equal when they are the same list.
+		if rv, ok := right.(*list); ok {
+			return lv == rv, nil
+		}
+	case *dictionary:
+		if rv, ok := right.(*dictionary); ok {
+			return lv == rv, nil
+		}
+	case *nativeFunction:
+		if rv, ok := right.(*nativeFunction); ok {
+			return lv == rv, nil
+		}
 	}
 
 	return false, nil
@@ -1,8 +1,10 @@
 package lox_test
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
@@ -149,6 +151,356 @@ func TestEvaluate(t *testing.T) {
 			expectedOut: "false",
 			expectedErr: "",
 		},
+		{
+			input:       "0x10 + 0b11 + 1_000 + 2.5e1",
+			expectedOut: "1044",
+			expectedErr: "",
+		},
+		{
+			input:       "7 % 3 + -7 % 3",
+			expectedOut: "0",
+			expectedErr: "",
+		},
+		{
+			input:       "2 ** 3 ** 2",
+			expectedOut: "512",
+			expectedErr: "",
+		},
+		{
+			input:       "-2 ** 2",
+			expectedOut: "-4",
+			expectedErr: "",
+		},
+		{
+			input:       "7 ~/ 2 + -7 ~/ 2",
+			expectedOut: "0",
+			expectedErr: "",
+		},
+		{
+			input:       "1 ~/ 0",
+			expectedOut: "",
+			expectedErr: "Division by zero.\n[line 1]",
+		},
+		{
+			input:       "int == int",
+			expectedOut: "true",
+			expectedErr: "",
+		},
+		{
+			input:       "int != float",
+			expectedOut: "true",
+			expectedErr: "",
+		},
+		{
+			input:       "1 / 0",
+			expectedOut: "",
+			expectedErr: "Division by zero.\n[line 1]",
+		},
+		{
+			input:       "1.0 / 0",
+			expectedOut: "+Inf",
+			expectedErr: "",
+		},
+		{
+			input:       "(6 & 3) + (6 | 3) + (6 ^ 3) + ~5",
+			expectedOut: "8",
+			expectedErr: "",
+		},
+		{
+			input:       "1 << 4 | -16 >> 2",
+			expectedOut: "-4",
+			expectedErr: "",
+		},
+		{
+			input:       "1.5 & 1",
+			expectedOut: "",
+			expectedErr: "Operands must be two integers.\n[line 1]",
+		},
+		{
+			input:       "~0.5",
+			expectedOut: "",
+			expectedErr: "Operand must be an integer.\n[line 1]",
+		},
+		{
+			input:       "1 << 62 | -1 << 63 | 0 << 100",
+			expectedOut: "-4611686018427387904",
+			expectedErr: "",
+		},
+		{
+			input:       "1 << 63",
+			expectedOut: "",
+			expectedErr: "Integer overflow.\n[line 1]",
+		},
+		{
+			input:       "1 << 100",
+			expectedOut: "",
+			expectedErr: "Integer overflow.\n[line 1]",
+		},
+		{
+			input:       "3 << 62",
+			expectedOut: "",
+			expectedErr: "Integer overflow.\n[line 1]",
+		},
+		{
+			input:       "1 << -1",
+			expectedOut: "",
+			expectedErr: "Shift count must not be negative.\n[line 1]",
+		},
+		{
+			input:       "9007199254740993 + 1",
+			expectedOut: "9007199254740994",
+			expectedErr: "",
+		},
+		{
+			input:       "10 / 2",
+			expectedOut: "5",
+			expectedErr: "",
+		},
+		{
+			input:       "10 / 4",
+			expectedOut: "2.5",
+			expectedErr: "",
+		},
+		{
+			input:       "2 ** 62 + (2 ** 62 - 1)",
+			expectedOut: "9223372036854775807",
+			expectedErr: "",
+		},
+		{
+			input:       "9223372036854775807 + 1",
+			expectedOut: "",
+			expectedErr: "Integer overflow.\n[line 1]",
+		},
+		{
+			input:       "3037000500 * 3037000500",
+			expectedOut: "",
+			expectedErr: "Integer overflow.\n[line 1]",
+		},
+		{
+			input:       "2 ** 63",
+			expectedOut: "",
+			expectedErr: "Integer overflow.\n[line 1]",
+		},
+		{
+			input:       "7 % 0",
+			expectedOut: "",
+			expectedErr: "Division by zero.\n[line 1]",
+		},
+		{
+			input:       "1 == 1.0",
+			expectedOut: "true",
+			expectedErr: "",
+		},
+		{
+			input:       "1 + 0.5",
+			expectedOut: "1.5",
+			expectedErr: "",
+		},
+		{
+			input:       "0.1d + 0.2d",
+			expectedOut: "0.3",
+			expectedErr: "",
+		},
+		{
+			input:       "1.10d * 3",
+			expectedOut: "3.30",
+			expectedErr: "",
+		},
+		{
+			input:       "10.00d / 4",
+			expectedOut: "2.50",
+			expectedErr: "",
+		},
+		{
+			input:       "1d / 3",
+			expectedOut: "0.33333333333333333333333333333333",
+			expectedErr: "",
+		},
+		{
+			input:       "7n / 2n",
+			expectedOut: "3.5",
+			expectedErr: "",
+		},
+		{
+			input:       "(10n ** 30 + 1) / 3n",
+			expectedOut: "333333333333333333333333333333.66666666666666666666666666666667",
+			expectedErr: "",
+		},
+		{
+			input:       "1n / 0n",
+			expectedOut: "",
+			expectedErr: "Division by zero.\n[line 1]",
+		},
+		{
+			input:       "9223372036854775807n + 1",
+			expectedOut: "9223372036854775808",
+			expectedErr: "",
+		},
+		{
+			input:       "2n ** 64 - 1 == 18446744073709551615n",
+			expectedOut: "true",
+			expectedErr: "",
+		},
+		{
+			input:       "1n << 70 | 1",
+			expectedOut: "1180591620717411303425",
+			expectedErr: "",
+		},
+		{
+			input:       "1 < 2",
+			expectedOut: "true",
+			expectedErr: "",
+		},
+		{
+			input:       "0.1d == 0.1",
+			expectedOut: "false",
+			expectedErr: "",
+		},
+		{
+			input:       "1.50d == 1.5d",
+			expectedOut: "true",
+			expectedErr: "",
+		},
+		{
+			input:       "1.5d < 2n",
+			expectedOut: "true",
+			expectedErr: "",
+		},
+		{
+			input:       "1.5d + 1.5",
+			expectedOut: "",
+			expectedErr: "Operands must not mix decimals and floats, convert one of them with decimal() or float().\n[line 1]",
+		},
+		{
+			input:       "decimal(\"19.99\") * 3",
+			expectedOut: "59.97",
+			expectedErr: "",
+		},
+		{
+			input:       "decimal(0.1) + decimal(0.2)",
+			expectedOut: "0.3",
+			expectedErr: "",
+		},
+		{
+			input:       "bigint(\"123456789012345678901234567890\") + int(\"1\")",
+			expectedOut: "123456789012345678901234567891",
+			expectedErr: "",
+		},
+		{
+			input:       "float(1.25d) + int(2.7)",
+			expectedOut: "3.25",
+			expectedErr: "",
+		},
+		{
+			input:       "int(99999999999999999999n)",
+			expectedOut: "",
+			expectedErr: "Cannot convert 99999999999999999999 to int.\n[line 1]",
+		},
+		{
+			input:       "decimal(\"abc\")",
+			expectedOut: "",
+			expectedErr: "Cannot convert \"abc\" to decimal.\n[line 1]",
+		},
+		{
+			input:       "decimal(\"1e99999999999\")",
+			expectedOut: "",
+			expectedErr: "Cannot convert \"1e99999999999\" to decimal.\n[line 1]",
+		},
+		{
+			input:       "decimal(1, 2)",
+			expectedOut: "",
+			expectedErr: "Expected 1 arguments but got 2.\n[line 1]",
+		},
+		{
+			input:       "foo",
+			expectedOut: "",
+			expectedErr: "Undefined variable 'foo'.\n[line 1]",
+		},
+		{
+			input:       "\"foo\"()",
+			expectedOut: "",
+			expectedErr: "Can only call functions and classes.\n[line 1]",
+		},
+		{
+			input:       "count += 1",
+			expectedOut: "",
+			expectedErr: "Undefined variable 'count'.\n[line 1]",
+		},
+		{
+			input:       "foo = 1",
+			expectedOut: "",
+			expectedErr: "Undefined variable 'foo'.\n[line 1]",
+		},
+		{
+			input:       "int = 1",
+			expectedOut: "",
+			expectedErr: "Cannot assign to 'int'.\n[line 1]",
+		},
+		{
+			input:       "int++",
+			expectedOut: "",
+			expectedErr: "Operand must be a number.\n[line 1]",
+		},
+		{
+			input:       "- -1",
+			expectedOut: "1",
+			expectedErr: "",
+		},
+		{
+			input:       "nil ? 1 : false ? 2 : 3",
+			expectedOut: "3",
+			expectedErr: "",
+		},
+		{
+			input:       "1 < 2 ? \"yes\" : undefined",
+			expectedOut: "yes",
+			expectedErr: "",
+		},
+		{
+			input:       "nil ?? false ?? 3",
+			expectedOut: "false",
+			expectedErr: "",
+		},
+		{
+			input:       "nil ?? \"default\"",
+			expectedOut: "default",
+			expectedErr: "",
+		},
+		{
+			input:       "1 ?? undefined",
+			expectedOut: "1",
+			expectedErr: "",
+		},
+		{
+			input:       "nil?.server.port",
+			expectedOut: "<nil>",
+			expectedErr: "",
+		},
+		{
+			input:       "nil?.method(undefined)",
+			expectedOut: "<nil>",
+			expectedErr: "",
+		},
+		{
+			input:       "nil?.[undefined]",
+			expectedOut: "<nil>",
+			expectedErr: "",
+		},
+		{
+			input:       "nil?.name ?? \"default\"",
+			expectedOut: "default",
+			expectedErr: "",
+		},
+		{
+			input:       "\"config\"?.server",
+			expectedOut: "",
+			expectedErr: "Only instances have properties.\n[line 1]",
+		},
+		{
+			input:       "(nil?.server).port",
+			expectedOut: "",
+			expectedErr: "Only instances have properties.\n[line 1]",
+		},
 		{
 			input:       "-\"foo\"",
 			expectedOut: "",
@@ -190,6 +542,11 @@ func TestRun(t *testing.T) {
 		expectedOut string
 		expectedErr string
 	}{
+		{
+			input:       "print 1;\nprint 9223372036854775808;",
+			expectedOut: "",
+			expectedErr: "[line 2] Error: Integer literal is out of range: 9223372036854775808.\n",
+		},
 		{
 			input:       "print false != false;",
 			expectedOut: "false\n",
@@ -205,11 +562,161 @@ func TestRun(t *testing.T) {
 			expectedOut: "true\n",
 			expectedErr: "",
 		},
+		{
+			input:       "print \"say \\\"hi\\\"\\n\\tto \\u{1F600}\";",
+			expectedOut: "say \"hi\"\n\tto 😀\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print \"Hello ${\"world\"}, you have ${1 + 1} items and ${nil} ${\"nested ${true}\"}\";\nprint nil;",
+			expectedOut: "Hello world, you have 2 items and nil nested true\nnil\n",
+			expectedErr: "",
+		},
 		{
 			input:       "print \"the expression below is invalid\";\n49 + \"baz\";\nprint \"this should not be printed\";\n",
 			expectedOut: "the expression below is invalid\n",
 			expectedErr: "Operands must be two numbers or two strings.\n[line 2]",
 		},
+		{
+			input:       "print 1;\nbreak;",
+			expectedOut: "",
+			expectedErr: "[line 2] Error at 'break': Can't use 'break' outside of a loop.",
+		},
+		{
+			input:       "continue;",
+			expectedOut: "",
+			expectedErr: "[line 1] Error at 'continue': Can't use 'continue' outside of a loop.",
+		},
+		{
+			input:       "print [1, \"two\", nil, [true]];\nprint [1, 2, 3][-1];\nprint [1, 2][0] = 5;\nprint [1, 2][1] += 3;",
+			expectedOut: "[1, \"two\", nil, [true]]\n3\n5\n5\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print [1, 2].push(3).len();\nprint [1, 2, 3].pop();\nprint [1, 2, 3, 4].slice(1, -1);\nprint [1, 2].slice(-5, 5);",
+			expectedOut: "3\n3\n[2, 3]\n[1, 2]\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print [1, 2, 3].slice(1n, 2);\nprint [1, 2, 3].slice(-99999999999999999999n, 99999999999999999999n);",
+			expectedOut: "[2]\n[1, 2, 3]\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print [\"a, b\", \"c\"];\nprint {\"k\": [\"v\"], 1: \"1\"};\nprint \"a\";",
+			expectedOut: "[\"a, b\", \"c\"]\n{\"k\": [\"v\"], 1: \"1\"}\na\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print [\"1\", \"2\"].map(int);\nprint [1, 0, 2].filter(bigint).len();\nprint [] == [];",
+			expectedOut: "[1, 2]\n3\nfalse\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print {\"a\": 1, b: 2, 3: nil, true: false, nil: 0};\nprint {a: 1}[\"a\"];\nprint {a: 1}[\"z\"] ?? \"none\";\nprint {a: 1}[\"b\"] = 2;",
+			expectedOut: "{\"a\": 1, \"b\": 2, 3: nil, true: false, nil: 0}\n1\nnone\n2\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print {1: \"int\"}[1.0];\nprint {1: \"int\"}[1n];\nprint {1: \"int\"}[1.00d];\nprint {1: \"a\", 1.0: \"b\", \"1\": \"c\"};",
+			expectedOut: "int\nint\nint\n{1: \"b\", \"1\": \"c\"}\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print {a: 1}.has(\"a\");\nprint {b: 2, a: 1}.keys();\nprint {b: 2, a: 1}.values();\nprint {a: 1, b: 2}.delete(\"a\");\nprint {a: 1}.delete(\"b\");\nprint {} == {};",
+			expectedOut: "true\n[\"b\", \"a\"]\n[2, 1]\ntrue\nfalse\nfalse\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print {[]: 1};",
+			expectedOut: "",
+			expectedErr: "Map keys must be numbers, strings, booleans or nil.\n[line 1]",
+		},
+		{
+			input:       "print {}[0.0 / 0.0] = 1;",
+			expectedOut: "",
+			expectedErr: "NaN cannot be a map key.\n[line 1]",
+		},
+		{
+			input:       "{}\n{ print 1; { print 2; } }\n{a: 1};",
+			expectedOut: "1\n2\n",
+			expectedErr: "",
+		},
+		{
+			input:       "for (x in [1, 2]) print x;\nfor (i, x in [\"a\", \"b\"]) print \"${i} ${x}\";\nfor (c in \"hé\") print c;",
+			expectedOut: "1\n2\n0 a\n1 b\nh\né\n",
+			expectedErr: "",
+		},
+		{
+			input:       "for (k in {a: 1, b: 2}) print k;\nfor (k, v in {b: 2, a: 1}) print \"${k}=${v}\";",
+			expectedOut: "a\nb\nb=2\na=1\n",
+			expectedErr: "",
+		},
+		{
+			input:       "for (x in [1, 2, 3, 4]) {\n  x += 10;\n  for (y in [1, 2]) { print y; break; }\n  print x;\n  continue;\n  print \"never\";\n}",
+			expectedOut: "1\n11\n1\n12\n1\n13\n1\n14\n",
+			expectedErr: "",
+		},
+		{
+			input:       "for (int in [\"2\"]) print int;\nprint int(\"3\");",
+			expectedOut: "2\n3\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print \"start\";\nfor (x in 42) print x;",
+			expectedOut: "start\n",
+			expectedErr: "Can only iterate over lists, maps and strings.\n[line 2]",
+		},
+		{
+			input:       "for (a, a in {}) print a;",
+			expectedOut: "",
+			expectedErr: "[line 1] Error at 'a': Already a variable with this name in this scope.",
+		},
+		{
+			input:       "for (x of items) print x;",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect 'in' after loop variables.",
+		},
+		{
+			input:       "for (xs in [[0, 1, 2, 3]]) { xs[xs.pop() - 3] += 10; print xs; }\nfor (xs in [[0, 1, 2, 3]]) { print xs[xs.pop() - 3]++; print xs; }\nfor (m in [{}]) { m[m.len()] = m.len(); print m; }",
+			expectedOut: "[10, 1, 2]\n0\n[1, 1, 2]\n{0: 0}\n",
+			expectedErr: "",
+		},
+		{
+			input:       "print [1, 2];\nprint [1, 2][2];",
+			expectedOut: "[1, 2]\n",
+			expectedErr: "Index out of bounds.\n[line 2]",
+		},
+		{
+			input:       "print [1, 2, 3][1n];\nprint [1, 2, 3][-1n];\nprint [1][100000000000000000000n];",
+			expectedOut: "2\n3\n",
+			expectedErr: "Index out of bounds.\n[line 3]",
+		},
+		{
+			input:       "[1][-2] = 0;",
+			expectedOut: "",
+			expectedErr: "Index out of bounds.\n[line 1]",
+		},
+		{
+			input:       "print [1][0.0];",
+			expectedOut: "",
+			expectedErr: "Index must be an integer.\n[line 1]",
+		},
+		{
+			input:       "[].pop();",
+			expectedOut: "",
+			expectedErr: "Cannot pop from an empty list.\n[line 1]",
+		},
+		{
+			input:       "[].size();",
+			expectedOut: "",
+			expectedErr: "Undefined property 'size'.\n[line 1]",
+		},
+		{
+			input:       "\"abc\"[0];",
+			expectedOut: "",
+			expectedErr: "Only lists and maps can be indexed.\n[line 1]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -217,8 +724,8 @@ func TestRun(t *testing.T) {
 			input := bytes.NewReader([]byte(tt.input))
 			output := bytes.NewBuffer([]byte(""))
 
-			l := lox.NewLox()
-			err := l.Run(input, output)
+			l := lox.NewLox(lox.WithStdout(output))
+			err := l.Execute(input)
 
 			if err != nil && tt.expectedErr == "" {
 				t.Fatalf("did not expect error, but got: %v", err)
@@ -241,3 +748,107 @@ func TestRun(t *testing.T) {
 	}
 
 }
+
+func TestRunWritesToConfiguredStdout(t *testing.T) {
+	first := bytes.NewBuffer([]byte(""))
+	second := bytes.NewBuffer([]byte(""))
+
+	err := lox.NewLox(lox.WithStdout(first)).Execute(bytes.NewReader([]byte("print \"first\";")))
+	if err != nil {
+		t.Fatalf("did not expect error, but got: %v", err)
+	}
+
+	err = lox.NewLox(lox.WithStdout(second)).Execute(bytes.NewReader([]byte("print \"second\";")))
+	if err != nil {
+		t.Fatalf("did not expect error, but got: %v", err)
+	}
+
+	if first.String() != "first\n" {
+		t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", "first\n", first.String())
+	}
+
+	if second.String() != "second\n" {
+		t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", "second\n", second.String())
+	}
+}
+
+func TestRunWritesToOutput(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte(""))
+	output := bytes.NewBuffer([]byte(""))
+
+	err := lox.NewLox(lox.WithStdout(stdout)).Run(bytes.NewReader([]byte("print \"out\";")), output)
+	if err != nil {
+		t.Fatalf("did not expect error, but got: %v", err)
+	}
+
+	if output.String() != "out\n" {
+		t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", "out\n", output.String())
+	}
+
+	if stdout.String() != "" {
+		t.Errorf("did not expect output on stdout, but got: %q", stdout.String())
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	inputReader, inputWriter := io.Pipe()
+	outputReader, outputWriter := io.Pipe()
+
+	l := lox.NewLox(lox.WithStdout(outputWriter))
+
+	done := make(chan error)
+	go func() {
+		done <- l.RunStream(inputReader)
+		outputWriter.Close()
+	}()
+
+	output := bufio.NewReader(outputReader)
+
+	// The first statement has to be executed before the rest of the input is written.
+	fmt.Fprint(inputWriter, "print 1;")
+	line, err := output.ReadString('\n')
+	if err != nil {
+		t.Fatalf("did not expect error, but got: %v", err)
+	}
+	if line != "1\n" {
+		t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", "1\n", line)
+	}
+
+	// A loop with a block body is not followed by a semicolon, it ends with the closing brace.
+	fmt.Fprint(inputWriter, " for (x in [2, 3]) { print x; }")
+	for _, expected := range []string{"2\n", "3\n"} {
+		line, err = output.ReadString('\n')
+		if err != nil {
+			t.Fatalf("did not expect error, but got: %v", err)
+		}
+		if line != expected {
+			t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", expected, line)
+		}
+	}
+
+	fmt.Fprint(inputWriter, "\nprint \"first\";\nprint ")
+	line, err = output.ReadString('\n')
+	if err != nil {
+		t.Fatalf("did not expect error, but got: %v", err)
+	}
+	if line != "first\n" {
+		t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", "first\n", line)
+	}
+
+	fmt.Fprint(inputWriter, "\"second\";\n49 + \"baz\";")
+	line, err = output.ReadString('\n')
+	if err != nil {
+		t.Fatalf("did not expect error, but got: %v", err)
+	}
+	if line != "second\n" {
+		t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", "second\n", line)
+	}
+
+	expectedErr := "Operands must be two numbers or two strings.\n[line 4]"
+	err = <-done
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("\nexpected error:\n%q\ngot:\n%q\n", expectedErr, err)
+	}
+
+	inputWriter.Close()
+}
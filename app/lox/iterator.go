@@ -0,0 +1,66 @@
+package lox
+
+// iterator goes through the items of a value in a `for-in` loop.
+// The key is the position of the item for the lists and the strings, so `for (i, x in items)` gets both.
+// The items are the ones the value had when the loop started, the changes made in the loop body do not affect the iteration.
+type iterator interface {
+	next() (key, value any, ok bool)
+}
+
+// iteratorOf returns the iterator of the value, or false when the value can not be iterated over.
+func iteratorOf(v any) (iterator, bool) {
+	switch v := v.(type) {
+	case *list:
+		return &listIterator{elements: append([]any{}, v.elements...)}, true
+	case *dictionary:
+		return &mapIterator{entries: append([]entry{}, v.entries...)}, true
+	case string:
+		return &stringIterator{characters: []rune(v)}, true
+	default:
+		return nil, false
+	}
+}
+
+type listIterator struct {
+	elements []any
+	position int
+}
+
+func (it *listIterator) next() (any, any, bool) {
+	if it.position >= len(it.elements) {
+		return nil, nil, false
+	}
+
+	it.position++
+	return int64(it.position - 1), it.elements[it.position-1], true
+}
+
+type mapIterator struct {
+	entries  []entry
+	position int
+}
+
+func (it *mapIterator) next() (any, any, bool) {
+	if it.position >= len(it.entries) {
+		return nil, nil, false
+	}
+
+	it.position++
+	e := it.entries[it.position-1]
+	return e.key, e.value, true
+}
+
+// stringIterator goes through the characters of the string, every character is a string of its own.
+type stringIterator struct {
+	characters []rune
+	position   int
+}
+
+func (it *stringIterator) next() (any, any, bool) {
+	if it.position >= len(it.characters) {
+		return nil, nil, false
+	}
+
+	it.position++
+	return int64(it.position - 1), string(it.characters[it.position-1]), true
+}
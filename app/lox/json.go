@@ -0,0 +1,646 @@
+package lox
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONSchemaVersion is the version of the JSON documents produced by the functions below.
+// It changes whenever a field is removed or its meaning changes, see docs/json-output.md.
+const JSONSchemaVersion = 1
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// The end of the span is exclusive, it points right after the last character.
+type jsonSpan struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonToken struct {
+	Type    TokenType `json:"type"`
+	Lexeme  string    `json:"lexeme"`
+	Literal any       `json:"literal"`
+	Line    int       `json:"line"`
+	Column  int       `json:"column"`
+}
+
+type jsonError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+}
+
+type jsonTokensDocument struct {
+	Version int         `json:"version"`
+	Tokens  []jsonToken `json:"tokens"`
+	Errors  []jsonError `json:"errors"`
+}
+
+type jsonSyntaxDocument struct {
+	Version    int         `json:"version"`
+	Expression any         `json:"expression,omitempty"`
+	Errors     []jsonError `json:"errors,omitempty"`
+}
+
+// jsonProgramDocument always has the statements, even when the program is empty.
+type jsonProgramDocument struct {
+	Version    int   `json:"version"`
+	Statements []any `json:"statements"`
+}
+
+type jsonNode struct {
+	Kind string   `json:"kind"`
+	Span jsonSpan `json:"span"`
+}
+
+func (n jsonNode) span() jsonSpan {
+	return n.Span
+}
+
+type jsonPrintStatement struct {
+	jsonNode
+	Expression any `json:"expression"`
+}
+
+type jsonExprStatement struct {
+	jsonNode
+	Expression any `json:"expression"`
+}
+
+type jsonJumpStatement struct {
+	jsonNode
+}
+
+type jsonBlockStatement struct {
+	jsonNode
+	Statements []any `json:"statements"`
+}
+
+type jsonForInStatement struct {
+	jsonNode
+	Variables []string `json:"variables"`
+	Iterable  any      `json:"iterable"`
+	Body      any      `json:"body"`
+}
+
+type jsonBinaryExpression struct {
+	jsonNode
+	Operator string `json:"operator"`
+	Left     any    `json:"left"`
+	Right    any    `json:"right"`
+}
+
+type jsonGroupingExpression struct {
+	jsonNode
+	Expression any `json:"expression"`
+}
+
+type jsonLiteralExpression struct {
+	jsonNode
+	Value any `json:"value"`
+}
+
+type jsonUnaryExpression struct {
+	jsonNode
+	Operator string `json:"operator"`
+	Right    any    `json:"right"`
+}
+
+type jsonInterpolationExpression struct {
+	jsonNode
+	Parts []any `json:"parts"`
+}
+
+type jsonVariableExpression struct {
+	jsonNode
+	Name string `json:"name"`
+}
+
+type jsonCallExpression struct {
+	jsonNode
+	Callee    any   `json:"callee"`
+	Arguments []any `json:"arguments"`
+}
+
+type jsonCompoundAssignExpression struct {
+	jsonNode
+	Operator string `json:"operator"`
+	Target   any    `json:"target"`
+	Value    any    `json:"value"`
+}
+
+type jsonUpdateExpression struct {
+	jsonNode
+	Operator string `json:"operator"`
+	Prefix   bool   `json:"prefix"`
+	Target   any    `json:"target"`
+}
+
+type jsonConditionalExpression struct {
+	jsonNode
+	Condition any `json:"condition"`
+	Then      any `json:"then"`
+	Else      any `json:"else"`
+}
+
+type jsonCoalesceExpression struct {
+	jsonNode
+	Left  any `json:"left"`
+	Right any `json:"right"`
+}
+
+type jsonGetExpression struct {
+	jsonNode
+	Object   any    `json:"object"`
+	Name     string `json:"name"`
+	Optional bool   `json:"optional"`
+}
+
+type jsonIndexExpression struct {
+	jsonNode
+	Object   any  `json:"object"`
+	Index    any  `json:"index"`
+	Optional bool `json:"optional"`
+}
+
+type jsonListExpression struct {
+	jsonNode
+	Elements []any `json:"elements"`
+}
+
+type jsonMapExpression struct {
+	jsonNode
+	Entries []jsonMapEntry `json:"entries"`
+}
+
+type jsonMapEntry struct {
+	Key   any `json:"key"`
+	Value any `json:"value"`
+}
+
+type jsonAssignExpression struct {
+	jsonNode
+	Target any `json:"target"`
+	Value  any `json:"value"`
+}
+
+// FormatTokensJSON formats the tokens and the errors of the tokenizer as a JSON document.
+func FormatTokensJSON(result TokenizeResult) (string, error) {
+	document := jsonTokensDocument{
+		Version: JSONSchemaVersion,
+		Tokens:  []jsonToken{},
+		Errors:  []jsonError{},
+	}
+
+	for _, t := range result.Tokens {
+		lexeme := ""
+		if t.Lexeme != nil {
+			lexeme = *t.Lexeme
+		}
+
+		document.Tokens = append(document.Tokens, jsonToken{
+			Type:    t.Type,
+			Lexeme:  lexeme,
+			Literal: t.Literal,
+			Line:    t.Line,
+			Column:  t.Column,
+		})
+	}
+
+	for _, e := range result.Errors {
+		document.Errors = append(document.Errors, jsonError{Message: e.Message, Line: e.Line, Column: e.Column})
+	}
+
+	return marshalJSON(document)
+}
+
+// FormatExpressionJSON formats the syntax tree of the expression as a JSON document.
+func FormatExpressionJSON(expr Expression) (string, error) {
+	node, err := expr.accept(&jsonPrinter{})
+	if err != nil {
+		return "", fmt.Errorf("failed to format expression: %w", err)
+	}
+
+	return marshalJSON(jsonSyntaxDocument{Version: JSONSchemaVersion, Expression: node})
+}
+
+// FormatStatementsJSON formats the syntax tree of the whole program as a JSON document.
+func FormatStatementsJSON(statements []Statement) (string, error) {
+	nodes := []any{}
+	printer := &jsonPrinter{}
+
+	for _, statement := range statements {
+		node, err := statement.accept(printer)
+		if err != nil {
+			return "", fmt.Errorf("failed to format statements: %w", err)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return marshalJSON(jsonProgramDocument{Version: JSONSchemaVersion, Statements: nodes})
+}
+
+// FormatSyntaxErrorJSON formats the error of the parser as a JSON document.
+func FormatSyntaxErrorJSON(err SyntaxError) (string, error) {
+	return marshalJSON(jsonSyntaxDocument{
+		Version: JSONSchemaVersion,
+		Errors:  []jsonError{{Message: err.message, Line: err.line}},
+	})
+}
+
+// FormatTokenErrorJSON formats the lexical error that stopped the parser as a JSON document, the same way as the syntax errors.
+func FormatTokenErrorJSON(err UnexpectedTokenError) (string, error) {
+	return marshalJSON(jsonSyntaxDocument{
+		Version: JSONSchemaVersion,
+		Errors:  []jsonError{{Message: err.Message, Line: err.Line, Column: err.Column}},
+	})
+}
+
+func marshalJSON(document any) (string, error) {
+	out, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(out) + "\n", nil
+}
+
+// jsonPrinter converts the syntax tree into the JSON nodes.
+// Every node is described by its kind, its span in the source code and the fields specific to that kind.
+type jsonPrinter struct{}
+
+func (p *jsonPrinter) visitPrintStatement(statement *printStatement) (any, error) {
+	expr, err := statement.expr.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonPrintStatement{
+		jsonNode:   jsonNode{Kind: "PrintStatement", Span: spanOf(statement.keyword, statement.semicolon)},
+		Expression: expr,
+	}, nil
+}
+
+func (p *jsonPrinter) visitExprStatement(statement *exprStatement) (any, error) {
+	expr, err := statement.expr.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonExprStatement{
+		jsonNode:   jsonNode{Kind: "ExpressionStatement", Span: jsonSpan{Start: nodeSpan(expr).Start, End: endOf(statement.semicolon)}},
+		Expression: expr,
+	}, nil
+}
+
+func (p *jsonPrinter) visitBreakStatement(statement *breakStatement) (any, error) {
+	return jsonJumpStatement{jsonNode{Kind: "BreakStatement", Span: spanOf(statement.keyword, statement.semicolon)}}, nil
+}
+
+func (p *jsonPrinter) visitContinueStatement(statement *continueStatement) (any, error) {
+	return jsonJumpStatement{jsonNode{Kind: "ContinueStatement", Span: spanOf(statement.keyword, statement.semicolon)}}, nil
+}
+
+func (p *jsonPrinter) visitBlockStatement(statement *blockStatement) (any, error) {
+	statements := []any{}
+	for _, s := range statement.statements {
+		out, err := s.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		statements = append(statements, out)
+	}
+
+	return jsonBlockStatement{
+		jsonNode:   jsonNode{Kind: "BlockStatement", Span: spanOf(statement.leftBrace, statement.rightBrace)},
+		Statements: statements,
+	}, nil
+}
+
+func (p *jsonPrinter) visitForInStatement(statement *forInStatement) (any, error) {
+	var variables []string
+	for _, variable := range statement.variables {
+		variables = append(variables, *variable.Lexeme)
+	}
+
+	iterable, err := statement.iterable.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	body, err := statement.body.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonForInStatement{
+		jsonNode:  jsonNode{Kind: "ForInStatement", Span: jsonSpan{Start: startOf(statement.keyword), End: nodeSpan(body).End}},
+		Variables: variables,
+		Iterable:  iterable,
+		Body:      body,
+	}, nil
+}
+
+func (p *jsonPrinter) visitBinaryExpression(expr *binaryExpression) (any, error) {
+	left, err := expr.Left.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	right, err := expr.Right.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonBinaryExpression{
+		jsonNode: jsonNode{Kind: "BinaryExpression", Span: jsonSpan{Start: nodeSpan(left).Start, End: nodeSpan(right).End}},
+		Operator: *expr.Operator.Lexeme,
+		Left:     left,
+		Right:    right,
+	}, nil
+}
+
+func (p *jsonPrinter) visitGroupingExpression(expr *groupingExpression) (any, error) {
+	inner, err := expr.Expression.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonGroupingExpression{
+		jsonNode:   jsonNode{Kind: "GroupingExpression", Span: spanOf(expr.LeftParen, expr.RightParen)},
+		Expression: inner,
+	}, nil
+}
+
+func (p *jsonPrinter) visitLiteralExpression(expr *literalExpression) (any, error) {
+	return jsonLiteralExpression{
+		jsonNode: jsonNode{Kind: "LiteralExpression", Span: spanOf(expr.Token, expr.Token)},
+		Value:    expr.Value,
+	}, nil
+}
+
+func (p *jsonPrinter) visitUnaryExpression(expr *unaryExpression) (any, error) {
+	right, err := expr.Right.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonUnaryExpression{
+		jsonNode: jsonNode{Kind: "UnaryExpression", Span: jsonSpan{Start: startOf(expr.Operator), End: nodeSpan(right).End}},
+		Operator: *expr.Operator.Lexeme,
+		Right:    right,
+	}, nil
+}
+
+func (p *jsonPrinter) visitInterpolationExpression(expr *interpolationExpression) (any, error) {
+	var parts []any
+	for _, part := range expr.Parts {
+		out, err := part.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, out)
+	}
+
+	return jsonInterpolationExpression{
+		jsonNode: jsonNode{Kind: "InterpolationExpression", Span: jsonSpan{Start: nodeSpan(parts[0]).Start, End: nodeSpan(parts[len(parts)-1]).End}},
+		Parts:    parts,
+	}, nil
+}
+
+func (p *jsonPrinter) visitVariableExpression(expr *variableExpression) (any, error) {
+	return jsonVariableExpression{
+		jsonNode: jsonNode{Kind: "VariableExpression", Span: spanOf(expr.Name, expr.Name)},
+		Name:     *expr.Name.Lexeme,
+	}, nil
+}
+
+func (p *jsonPrinter) visitCallExpression(expr *callExpression) (any, error) {
+	callee, err := expr.Callee.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := []any{}
+	for _, argument := range expr.Arguments {
+		out, err := argument.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = append(arguments, out)
+	}
+
+	return jsonCallExpression{
+		jsonNode:  jsonNode{Kind: "CallExpression", Span: jsonSpan{Start: nodeSpan(callee).Start, End: endOf(expr.Paren)}},
+		Callee:    callee,
+		Arguments: arguments,
+	}, nil
+}
+
+func (p *jsonPrinter) visitCompoundAssignExpression(expr *compoundAssignExpression) (any, error) {
+	target, err := expr.Target.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	value, err := expr.Value.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonCompoundAssignExpression{
+		jsonNode: jsonNode{Kind: "CompoundAssignExpression", Span: jsonSpan{Start: nodeSpan(target).Start, End: nodeSpan(value).End}},
+		Operator: *expr.Operator.Lexeme,
+		Target:   target,
+		Value:    value,
+	}, nil
+}
+
+func (p *jsonPrinter) visitUpdateExpression(expr *updateExpression) (any, error) {
+	target, err := expr.Target.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	span := jsonSpan{Start: nodeSpan(target).Start, End: endOf(expr.Operator)}
+	if expr.Prefix {
+		span = jsonSpan{Start: startOf(expr.Operator), End: nodeSpan(target).End}
+	}
+
+	return jsonUpdateExpression{
+		jsonNode: jsonNode{Kind: "UpdateExpression", Span: span},
+		Operator: *expr.Operator.Lexeme,
+		Prefix:   expr.Prefix,
+		Target:   target,
+	}, nil
+}
+
+func (p *jsonPrinter) visitConditionalExpression(expr *conditionalExpression) (any, error) {
+	condition, err := expr.Condition.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	thenBranch, err := expr.Then.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	elseBranch, err := expr.Else.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonConditionalExpression{
+		jsonNode:  jsonNode{Kind: "ConditionalExpression", Span: jsonSpan{Start: nodeSpan(condition).Start, End: nodeSpan(elseBranch).End}},
+		Condition: condition,
+		Then:      thenBranch,
+		Else:      elseBranch,
+	}, nil
+}
+
+func (p *jsonPrinter) visitCoalesceExpression(expr *coalesceExpression) (any, error) {
+	left, err := expr.Left.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	right, err := expr.Right.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonCoalesceExpression{
+		jsonNode: jsonNode{Kind: "CoalesceExpression", Span: jsonSpan{Start: nodeSpan(left).Start, End: nodeSpan(right).End}},
+		Left:     left,
+		Right:    right,
+	}, nil
+}
+
+func (p *jsonPrinter) visitGetExpression(expr *getExpression) (any, error) {
+	object, err := expr.Object.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonGetExpression{
+		jsonNode: jsonNode{Kind: "GetExpression", Span: jsonSpan{Start: nodeSpan(object).Start, End: endOf(expr.Name)}},
+		Object:   object,
+		Name:     *expr.Name.Lexeme,
+		Optional: expr.Optional,
+	}, nil
+}
+
+func (p *jsonPrinter) visitIndexExpression(expr *indexExpression) (any, error) {
+	object, err := expr.Object.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	index, err := expr.Index.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonIndexExpression{
+		jsonNode: jsonNode{Kind: "IndexExpression", Span: jsonSpan{Start: nodeSpan(object).Start, End: endOf(expr.Bracket)}},
+		Object:   object,
+		Index:    index,
+		Optional: expr.Optional,
+	}, nil
+}
+
+func (p *jsonPrinter) visitListExpression(expr *listExpression) (any, error) {
+	elements := []any{}
+	for _, element := range expr.Elements {
+		out, err := element.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, out)
+	}
+
+	return jsonListExpression{
+		jsonNode: jsonNode{Kind: "ListExpression", Span: spanOf(expr.LeftBracket, expr.RightBracket)},
+		Elements: elements,
+	}, nil
+}
+
+func (p *jsonPrinter) visitMapExpression(expr *mapExpression) (any, error) {
+	entries := []jsonMapEntry{}
+	for i := range expr.Keys {
+		key, err := expr.Keys[i].accept(p)
+		if err != nil {
+			return nil, err
+		}
+		value, err := expr.Values[i].accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, jsonMapEntry{Key: key, Value: value})
+	}
+
+	return jsonMapExpression{
+		jsonNode: jsonNode{Kind: "MapExpression", Span: spanOf(expr.LeftBrace, expr.RightBrace)},
+		Entries:  entries,
+	}, nil
+}
+
+func (p *jsonPrinter) visitAssignExpression(expr *assignExpression) (any, error) {
+	target, err := expr.Target.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	value, err := expr.Value.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonAssignExpression{
+		jsonNode: jsonNode{Kind: "AssignExpression", Span: jsonSpan{Start: nodeSpan(target).Start, End: nodeSpan(value).End}},
+		Target:   target,
+		Value:    value,
+	}, nil
+}
+
+// The optional chain is not a node of its own, the `optional` fields of its links already describe it.
+func (p *jsonPrinter) visitOptionalChainExpression(expr *optionalChainExpression) (any, error) {
+	return expr.Expression.accept(p)
+}
+
+// nodeSpan returns the span of a node that has already been converted.
+// All the nodes embed the `jsonNode`, so they all have the `span` method.
+func nodeSpan(node any) jsonSpan {
+	return node.(interface{ span() jsonSpan }).span()
+}
+
+func spanOf(first Token, last Token) jsonSpan {
+	return jsonSpan{Start: startOf(first), End: endOf(last)}
+}
+
+func startOf(t Token) jsonPosition {
+	return jsonPosition{Line: t.Line, Column: t.Column}
+}
+
+// endOf returns the position right after the last character of the token.
+func endOf(t Token) jsonPosition {
+	end := startOf(t)
+	if t.Lexeme == nil {
+		return end
+	}
+
+	for _, r := range *t.Lexeme {
+		if r == '\n' {
+			end.Line += 1
+			end.Column = 1
+			continue
+		}
+
+		end.Column += 1
+	}
+
+	return end
+}
@@ -0,0 +1,325 @@
+package lox_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+)
+
+func TestFormatTokensJSON(t *testing.T) {
+	l := lox.NewLox()
+	result, err := l.Tokenize(strings.NewReader("\"ż\" 0x1F\n#"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := lox.FormatTokensJSON(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedOut := `{
+		"version": 1,
+		"tokens": [
+			{"type": "STRING", "lexeme": "\"ż\"", "literal": "ż", "line": 1, "column": 1},
+			{"type": "NUMBER", "lexeme": "0x1F", "literal": 31, "line": 1, "column": 5},
+			{"type": "EOF", "lexeme": "", "literal": null, "line": 2, "column": 2}
+		],
+		"errors": [{"message": "Unexpected character: #", "line": 2, "column": 1}]
+	}`
+	assertJSON(t, expectedOut, out)
+}
+
+func TestFormatExpressionJSON(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedOut string
+	}{
+		{
+			input: "-(1 + nil)",
+			expectedOut: `{
+				"version": 1,
+				"expression": {
+					"kind": "UnaryExpression",
+					"span": {"start": {"line": 1, "column": 1}, "end": {"line": 1, "column": 11}},
+					"operator": "-",
+					"right": {
+						"kind": "GroupingExpression",
+						"span": {"start": {"line": 1, "column": 2}, "end": {"line": 1, "column": 11}},
+						"expression": {
+							"kind": "BinaryExpression",
+							"span": {"start": {"line": 1, "column": 3}, "end": {"line": 1, "column": 10}},
+							"operator": "+",
+							"left": {
+								"kind": "LiteralExpression",
+								"span": {"start": {"line": 1, "column": 3}, "end": {"line": 1, "column": 4}},
+								"value": 1
+							},
+							"right": {
+								"kind": "LiteralExpression",
+								"span": {"start": {"line": 1, "column": 7}, "end": {"line": 1, "column": 10}},
+								"value": null
+							}
+						}
+					}
+				}
+			}`,
+		},
+		{
+			input: "[1]",
+			expectedOut: `{
+				"version": 1,
+				"expression": {
+					"kind": "ListExpression",
+					"span": {"start": {"line": 1, "column": 1}, "end": {"line": 1, "column": 4}},
+					"elements": [
+						{
+							"kind": "LiteralExpression",
+							"span": {"start": {"line": 1, "column": 2}, "end": {"line": 1, "column": 3}},
+							"value": 1
+						}
+					]
+				}
+			}`,
+		},
+		{
+			input: "\"a\n${true}\"",
+			expectedOut: `{
+				"version": 1,
+				"expression": {
+					"kind": "InterpolationExpression",
+					"span": {"start": {"line": 1, "column": 1}, "end": {"line": 2, "column": 9}},
+					"parts": [
+						{
+							"kind": "LiteralExpression",
+							"span": {"start": {"line": 1, "column": 1}, "end": {"line": 2, "column": 3}},
+							"value": "a\n"
+						},
+						{
+							"kind": "LiteralExpression",
+							"span": {"start": {"line": 2, "column": 3}, "end": {"line": 2, "column": 7}},
+							"value": true
+						},
+						{
+							"kind": "LiteralExpression",
+							"span": {"start": {"line": 2, "column": 7}, "end": {"line": 2, "column": 9}},
+							"value": ""
+						}
+					]
+				}
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := lox.NewLox()
+			expr, err := l.ParseExpression(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			out, err := lox.FormatExpressionJSON(expr)
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			assertJSON(t, tt.expectedOut, out)
+		})
+	}
+}
+
+func TestFormatStatementsJSON(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedOut string
+	}{
+		{
+			input: "for (k, v in m) { print v; }",
+			expectedOut: `{
+				"version": 1,
+				"statements": [
+					{
+						"kind": "ForInStatement",
+						"span": {"start": {"line": 1, "column": 1}, "end": {"line": 1, "column": 29}},
+						"variables": ["k", "v"],
+						"iterable": {
+							"kind": "VariableExpression",
+							"span": {"start": {"line": 1, "column": 14}, "end": {"line": 1, "column": 15}},
+							"name": "m"
+						},
+						"body": {
+							"kind": "BlockStatement",
+							"span": {"start": {"line": 1, "column": 17}, "end": {"line": 1, "column": 29}},
+							"statements": [
+								{
+									"kind": "PrintStatement",
+									"span": {"start": {"line": 1, "column": 19}, "end": {"line": 1, "column": 27}},
+									"expression": {
+										"kind": "VariableExpression",
+										"span": {"start": {"line": 1, "column": 25}, "end": {"line": 1, "column": 26}},
+										"name": "v"
+									}
+								}
+							]
+						}
+					}
+				]
+			}`,
+		},
+		{
+			input: "for (x in m) { break; continue; }",
+			expectedOut: `{
+				"version": 1,
+				"statements": [
+					{
+						"kind": "ForInStatement",
+						"span": {"start": {"line": 1, "column": 1}, "end": {"line": 1, "column": 34}},
+						"variables": ["x"],
+						"iterable": {
+							"kind": "VariableExpression",
+							"span": {"start": {"line": 1, "column": 11}, "end": {"line": 1, "column": 12}},
+							"name": "m"
+						},
+						"body": {
+							"kind": "BlockStatement",
+							"span": {"start": {"line": 1, "column": 14}, "end": {"line": 1, "column": 34}},
+							"statements": [
+								{
+									"kind": "BreakStatement",
+									"span": {"start": {"line": 1, "column": 16}, "end": {"line": 1, "column": 22}}
+								},
+								{
+									"kind": "ContinueStatement",
+									"span": {"start": {"line": 1, "column": 23}, "end": {"line": 1, "column": 32}}
+								}
+							]
+						}
+					}
+				]
+			}`,
+		},
+		{
+			input: "{}",
+			expectedOut: `{
+				"version": 1,
+				"statements": [
+					{
+						"kind": "BlockStatement",
+						"span": {"start": {"line": 1, "column": 1}, "end": {"line": 1, "column": 3}},
+						"statements": []
+					}
+				]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := lox.NewLox()
+			statements, err := l.Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			out, err := lox.FormatStatementsJSON(statements)
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			assertJSON(t, tt.expectedOut, out)
+		})
+	}
+}
+
+func TestFormatEmptyProgramJSON(t *testing.T) {
+	for _, input := range []string{"", "// only a comment\n"} {
+		t.Run(input, func(t *testing.T) {
+			l := lox.NewLox()
+			statements, err := l.Parse(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			out, err := lox.FormatStatementsJSON(statements)
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			assertJSON(t, `{"version": 1, "statements": []}`, out)
+		})
+	}
+}
+
+func TestFormatSyntaxErrorJSON(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedOut string
+	}{
+		{
+			input:       "(72+)",
+			expectedOut: `{"version": 1, "errors": [{"message": "Error at ')': Expect expression.", "line": 1}]}`,
+		},
+		{
+			input:       "print 1;\nprint 2;\nprint 3",
+			expectedOut: `{"version": 1, "errors": [{"message": "Expect ';' after value.", "line": 3}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := lox.NewLox()
+			// Same as the `parse` command, the error of the whole program is the one reported.
+			_, err := l.Parse(strings.NewReader(tt.input))
+
+			var syntaxError lox.SyntaxError
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("expected syntax error, got: %v", err)
+			}
+
+			out, err := lox.FormatSyntaxErrorJSON(syntaxError)
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			assertJSON(t, tt.expectedOut, out)
+		})
+	}
+}
+
+func TestFormatTokenErrorJSON(t *testing.T) {
+	l := lox.NewLox()
+	_, err := l.Parse(strings.NewReader("print 1;\nprint 9223372036854775808;"))
+
+	var tokenError lox.UnexpectedTokenError
+	if !errors.As(err, &tokenError) {
+		t.Fatalf("expected unexpected token error, got: %v", err)
+	}
+
+	out, err := lox.FormatTokenErrorJSON(tokenError)
+	if err != nil {
+		t.Fatalf("did not expect error, but got: %v", err)
+	}
+
+	assertJSON(t, `{"version": 1, "errors": [{"message": "Integer literal is out of range: 9223372036854775808.", "line": 2, "column": 7}]}`, out)
+}
+
+func assertJSON(t *testing.T, expected string, got string) {
+	t.Helper()
+
+	var expectedOut, out bytes.Buffer
+	if err := json.Compact(&expectedOut, []byte(expected)); err != nil {
+		t.Fatalf("invalid expected JSON: %v", err)
+	}
+	if err := json.Compact(&out, []byte(got)); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if out.String() != expectedOut.String() {
+		t.Errorf("\nexpected output:\n%s\ngot:\n%s\n", expectedOut.String(), out.String())
+	}
+}
@@ -0,0 +1,177 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// list is the value of a list literal, like `[1, 2, 3]`.
+// It is a pointer, so the changes made through one reference are seen by all the others.
+type list struct {
+	elements []any
+}
+
+func (l *list) String() string {
+	elements := make([]string, len(l.elements))
+	for i, element := range l.elements {
+		elements[i] = stringifyElement(element)
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// position converts the index into a position in the list, a negative index counts from the end, so -1 is the last element.
+func (l *list) position(index any) (int, error) {
+	var i int64
+	switch index := index.(type) {
+	case int64:
+		i = index
+	case *big.Int:
+		if !index.IsInt64() {
+			// Too big to be the position of any element.
+			return 0, errors.New("Index out of bounds.")
+		}
+		i = index.Int64()
+	default:
+		return 0, errors.New("Index must be an integer.")
+	}
+
+	if i < 0 {
+		i += int64(len(l.elements))
+	}
+
+	if i < 0 || i >= int64(len(l.elements)) {
+		return 0, errors.New("Index out of bounds.")
+	}
+
+	return int(i), nil
+}
+
+// method returns the native method bound to the list, like the `push` in `items.push(4)`.
+func (l *list) method(name string) (*nativeFunction, bool) {
+	switch name {
+	case "push":
+		return &nativeFunction{parameters: 1, fn: l.push}, true
+	case "pop":
+		return &nativeFunction{parameters: 0, fn: l.pop}, true
+	case "len":
+		return &nativeFunction{parameters: 0, fn: l.len}, true
+	case "slice":
+		return &nativeFunction{parameters: 2, fn: l.slice}, true
+	case "map":
+		return &nativeFunction{parameters: 1, fn: l.mapElements}, true
+	case "filter":
+		return &nativeFunction{parameters: 1, fn: l.filter}, true
+	default:
+		return nil, false
+	}
+}
+
+// push appends the element and returns the list, so the calls can be chained.
+func (l *list) push(arguments []any) (any, error) {
+	l.elements = append(l.elements, arguments[0])
+	return l, nil
+}
+
+// pop removes the last element and returns it.
+func (l *list) pop(arguments []any) (any, error) {
+	if len(l.elements) == 0 {
+		return nil, errors.New("Cannot pop from an empty list.")
+	}
+
+	last := l.elements[len(l.elements)-1]
+	l.elements = l.elements[:len(l.elements)-1]
+	return last, nil
+}
+
+func (l *list) len(arguments []any) (any, error) {
+	return int64(len(l.elements)), nil
+}
+
+// slice returns a new list with the elements from start up to, but not including, end.
+// Same as the indexes, negative bounds count from the end, and the bounds past the ends of the list are clamped.
+func (l *list) slice(arguments []any) (any, error) {
+	bounds := make([]int64, 2)
+	for i, argument := range arguments {
+		var bound int64
+		switch argument := argument.(type) {
+		case int64:
+			bound = argument
+		case *big.Int:
+			// The bounds that do not fit in int64 are past the ends of any list, so they are clamped like the others.
+			bound = argument.Int64()
+			if !argument.IsInt64() {
+				bound = int64(argument.Sign()) * math.MaxInt64
+			}
+		default:
+			return nil, errors.New("Slice bounds must be integers.")
+		}
+
+		if bound < 0 {
+			bound += int64(len(l.elements))
+		}
+		bounds[i] = min(max(bound, 0), int64(len(l.elements)))
+	}
+
+	start, end := bounds[0], max(bounds[0], bounds[1])
+	return &list{elements: append([]any{}, l.elements[start:end]...)}, nil
+}
+
+// mapElements returns a new list with the results of calling the function with each element.
+func (l *list) mapElements(arguments []any) (any, error) {
+	function, err := callback(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &list{elements: make([]any, 0, len(l.elements))}
+	for _, element := range l.elements {
+		value, err := function.call([]any{element})
+		if err != nil {
+			return nil, err
+		}
+
+		result.elements = append(result.elements, value)
+	}
+
+	return result, nil
+}
+
+// filter returns a new list with the elements for which the function returns a truthy value.
+func (l *list) filter(arguments []any) (any, error) {
+	function, err := callback(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &list{elements: []any{}}
+	for _, element := range l.elements {
+		keep, err := function.call([]any{element})
+		if err != nil {
+			return nil, err
+		}
+
+		if isTruthy(keep) {
+			result.elements = append(result.elements, element)
+		}
+	}
+
+	return result, nil
+}
+
+// callback checks that the argument is a function that can be called with a single element.
+func callback(v any) (callable, error) {
+	function, ok := v.(callable)
+	if !ok {
+		return nil, errors.New("Can only call functions and classes.")
+	}
+
+	if function.arity() != 1 {
+		return nil, fmt.Errorf("Expected %v arguments but got 1.", function.arity())
+	}
+
+	return function, nil
+}
@@ -1,7 +1,82 @@
+// Package lox implements the tokenizer, the parser and the interpreter of the Lox language.
+//
+// Many Lox instances can run at the same time, possibly on different goroutines, and they all share the package-level
+// lookup tables, like the lexemes of the tokens, the keywords, the native functions and the compound operators.
+// These tables are only ever read, nothing may modify them after the package is initialized.
+// TestConcurrentInstances checks that running programs on many instances at once leaves them unchanged.
 package lox
 
-type Lox struct{}
+import (
+	"io"
+	"os"
+)
 
-func NewLox() *Lox {
-	return &Lox{}
+type Lox struct {
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+
+	trivia bool
+}
+
+type Option func(l *Lox)
+
+// WithStdout sets the writer that the `print` statement writes to.
+func WithStdout(w io.Writer) Option {
+	return func(l *Lox) {
+		l.stdout = w
+	}
+}
+
+// WithStderr sets the writer that the host, like the command-line tool, reports the errors to.
+// The package itself never writes to it, the errors are returned to the caller.
+func WithStderr(w io.Writer) Option {
+	return func(l *Lox) {
+		l.stderr = w
+	}
+}
+
+// WithStdin sets the reader that the host, like the command-line tool, reads the source from when no file is given.
+// The package itself never reads from it, every method takes its input explicitly.
+func WithStdin(r io.Reader) Option {
+	return func(l *Lox) {
+		l.stdin = r
+	}
+}
+
+// WithTrivia makes the tokenizer attach the whitespace and comments around every token to that token.
+// With the trivia, the source code can be reconstructed from the tokens byte-for-byte.
+func WithTrivia() Option {
+	return func(l *Lox) {
+		l.trivia = true
+	}
+}
+
+func NewLox(opts ...Option) *Lox {
+	l := &Lox{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		stdin:  os.Stdin,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Stdout returns the writer that the `print` statements write to.
+func (l *Lox) Stdout() io.Writer {
+	return l.stdout
+}
+
+// Stderr returns the writer set with WithStderr, os.Stderr by default.
+func (l *Lox) Stderr() io.Writer {
+	return l.stderr
+}
+
+// Stdin returns the reader set with WithStdin, os.Stdin by default.
+func (l *Lox) Stdin() io.Reader {
+	return l.stdin
 }
@@ -0,0 +1,145 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// callable is a value that can be called, like `decimal("1.10")`.
+type callable interface {
+	arity() int
+	call(arguments []any) (any, error)
+}
+
+// nativeFunction is a function implemented in Go.
+// The returned errors become runtime errors reported at the line of the call.
+type nativeFunction struct {
+	parameters int
+	fn         func(arguments []any) (any, error)
+}
+
+func (n *nativeFunction) arity() int {
+	return n.parameters
+}
+
+func (n *nativeFunction) call(arguments []any) (any, error) {
+	return n.fn(arguments)
+}
+
+func (n *nativeFunction) String() string {
+	return "<native fn>"
+}
+
+// natives are the global functions available in every program.
+var natives = map[string]*nativeFunction{
+	"bigint":  {parameters: 1, fn: nativeBigInt},
+	"decimal": {parameters: 1, fn: nativeDecimal},
+	"float":   {parameters: 1, fn: nativeFloat},
+	"int":     {parameters: 1, fn: nativeInt},
+}
+
+func cannotConvert(v any, to string) error {
+	if s, ok := v.(string); ok {
+		return fmt.Errorf("Cannot convert %q to %s.", s, to)
+	}
+
+	return fmt.Errorf("Cannot convert %s to %s.", stringify(v), to)
+}
+
+// nativeBigInt converts the number or the string to *big.Int, the fractional part is truncated.
+func nativeBigInt(arguments []any) (any, error) {
+	switch v := arguments[0].(type) {
+	case int64:
+		return big.NewInt(v), nil
+	case *big.Int:
+		return v, nil
+	case decimal:
+		return v.rescale(0).unscaled, nil
+	case float64:
+		if !isFinite(v) {
+			return nil, cannotConvert(v, "bigint")
+		}
+		i, _ := big.NewFloat(math.Trunc(v)).Int(nil)
+		return i, nil
+	case string:
+		i, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, cannotConvert(v, "bigint")
+		}
+		return i, nil
+	default:
+		return nil, cannotConvert(v, "bigint")
+	}
+}
+
+// nativeDecimal converts the number or the string to decimal.
+// A float is converted from its shortest representation, so decimal(0.1) is 0.1 and not the exact binary value of the float.
+func nativeDecimal(arguments []any) (any, error) {
+	switch v := arguments[0].(type) {
+	case int64:
+		return decimalFromInt(big.NewInt(v)), nil
+	case *big.Int:
+		return decimalFromInt(v), nil
+	case decimal:
+		return v, nil
+	case float64:
+		if !isFinite(v) {
+			return nil, cannotConvert(v, "decimal")
+		}
+		d, _ := parseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+		return d, nil
+	case string:
+		d, ok := parseDecimal(v)
+		if !ok {
+			return nil, cannotConvert(v, "decimal")
+		}
+		return d, nil
+	default:
+		return nil, cannotConvert(v, "decimal")
+	}
+}
+
+// nativeFloat converts the number or the string to float64, big numbers are rounded to the nearest float.
+func nativeFloat(arguments []any) (any, error) {
+	switch v := arguments[0].(type) {
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, cannotConvert(v, "float")
+		}
+		return f, nil
+	default:
+		f, err := toF64(v)
+		if err != nil {
+			return nil, cannotConvert(v, "float")
+		}
+		return f, nil
+	}
+}
+
+// nativeInt converts the number or the string to int64, the fractional part is truncated.
+func nativeInt(arguments []any) (any, error) {
+	switch v := arguments[0].(type) {
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, cannotConvert(v, "int")
+		}
+		return i, nil
+	case float64:
+		if !isFinite(v) || math.Trunc(v) < math.MinInt64 || math.Trunc(v) >= math.MaxInt64 {
+			return nil, cannotConvert(v, "int")
+		}
+		return int64(v), nil
+	case int64, *big.Int, decimal:
+		i, _ := nativeBigInt(arguments)
+		if !i.(*big.Int).IsInt64() {
+			return nil, cannotConvert(v, "int")
+		}
+		return i.(*big.Int).Int64(), nil
+	default:
+		return nil, cannotConvert(v, "int")
+	}
+}
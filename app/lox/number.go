@@ -0,0 +1,498 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Numbers are int64, *big.Int, decimal or float64.
+// The integer literals evaluate to int64 and the arithmetic stays in integers as long as both operands are integers.
+// The operands are promoted along int64 -> *big.Int -> decimal, so that the result is exact.
+// Only the floats are inexact, an int64 or a *big.Int mixed with a float64 is promoted to float64,
+// but a decimal can not be mixed with a float64, since the result would silently lose the exactness.
+// Dividing an exact number by zero with `/`, `%` or `~/` is a "Division by zero." error, there is no exact infinity.
+// The floats follow IEEE 754 instead, so `1.0 / 0` is infinity, except for `~/` which has no integer result to give.
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int64, float64, *big.Int, decimal:
+		return true
+	default:
+		return false
+	}
+}
+
+// arithmetic evaluates the binary arithmetic operators, the operands must be numbers.
+func arithmetic(operator Token, left, right any) (any, error) {
+	// Two floats are by far the most common case, so they skip the promotion below.
+	if lf, ok := left.(float64); ok {
+		if rf, ok := right.(float64); ok {
+			return floatArithmetic(operator, lf, rf)
+		}
+	}
+
+	if !isNumber(left) || !isNumber(right) {
+		return nil, RuntimeError{line: operator.Line, message: "Operands must be two numbers."}
+	}
+
+	lv, lok := left.(int64)
+	rv, rok := right.(int64)
+	if lok && rok {
+		return integerArithmetic(operator, lv, rv)
+	}
+
+	_, lFloat := left.(float64)
+	_, rFloat := right.(float64)
+	_, lDecimal := left.(decimal)
+	_, rDecimal := right.(decimal)
+
+	switch {
+	case (lFloat && rDecimal) || (lDecimal && rFloat):
+		return nil, RuntimeError{line: operator.Line, message: "Operands must not mix decimals and floats, convert one of them with decimal() or float()."}
+	case lFloat || rFloat:
+		lf, _ := toF64(left)
+		rf, _ := toF64(right)
+		return floatArithmetic(operator, lf, rf)
+	case lDecimal || rDecimal:
+		return decimalArithmetic(operator, toDecimal(left), toDecimal(right))
+	default:
+		return bigArithmetic(operator, toBigInt(left), toBigInt(right))
+	}
+}
+
+func floatArithmetic(operator Token, lf, rf float64) (any, error) {
+	switch operator.Type {
+	case PLUS:
+		return lf + rf, nil
+	case MINUS:
+		return lf - rf, nil
+	case STAR:
+		return lf * rf, nil
+	case SLASH:
+		return lf / rf, nil
+	case PERCENT:
+		return math.Mod(lf, rf), nil
+	case STAR_STAR:
+		return math.Pow(lf, rf), nil
+	case TILDE_SLASH:
+		if rf == 0 {
+			return nil, RuntimeError{line: operator.Line, message: "Division by zero."}
+		}
+		return math.Trunc(lf / rf), nil
+	}
+
+	panic("unknown arithmetic operator")
+}
+
+func integerArithmetic(operator Token, lv, rv int64) (any, error) {
+	overflow := RuntimeError{line: operator.Line, message: "Integer overflow."}
+	divisionByZero := RuntimeError{line: operator.Line, message: "Division by zero."}
+
+	switch operator.Type {
+	case PLUS:
+		if (rv > 0 && lv > math.MaxInt64-rv) || (rv < 0 && lv < math.MinInt64-rv) {
+			return nil, overflow
+		}
+		return lv + rv, nil
+	case MINUS:
+		if (rv < 0 && lv > math.MaxInt64+rv) || (rv > 0 && lv < math.MinInt64+rv) {
+			return nil, overflow
+		}
+		return lv - rv, nil
+	case STAR:
+		product, ok := multiplyInt64(lv, rv)
+		if !ok {
+			return nil, overflow
+		}
+		return product, nil
+	case SLASH:
+		if rv == 0 {
+			return nil, divisionByZero
+		}
+		// The division stays in integers only when it is exact, `7 / 2` is 3.5 and not 3.
+		if lv%rv != 0 {
+			return float64(lv) / float64(rv), nil
+		}
+		if lv == math.MinInt64 && rv == -1 {
+			return nil, overflow
+		}
+		return lv / rv, nil
+	case PERCENT:
+		if rv == 0 {
+			return nil, divisionByZero
+		}
+		return lv % rv, nil
+	case STAR_STAR:
+		if rv < 0 {
+			return math.Pow(float64(lv), float64(rv)), nil
+		}
+
+		// For any other base the result overflows within 64 multiplications, so the loop is short even for huge exponents.
+		if lv == 0 || lv == 1 || lv == -1 {
+			return powerOfTrivialBase(lv, rv), nil
+		}
+
+		result := int64(1)
+		for range rv {
+			var ok bool
+			result, ok = multiplyInt64(result, lv)
+			if !ok {
+				return nil, overflow
+			}
+		}
+		return result, nil
+	case TILDE_SLASH:
+		if rv == 0 {
+			return nil, divisionByZero
+		}
+		if lv == math.MinInt64 && rv == -1 {
+			return nil, overflow
+		}
+		return lv / rv, nil
+	}
+
+	panic("unknown arithmetic operator")
+}
+
+// bigArithmetic follows the same rules as the int64 arithmetic, except that it never overflows.
+func bigArithmetic(operator Token, lv, rv *big.Int) (any, error) {
+	divisionByZero := RuntimeError{line: operator.Line, message: "Division by zero."}
+
+	switch operator.Type {
+	case PLUS:
+		return new(big.Int).Add(lv, rv), nil
+	case MINUS:
+		return new(big.Int).Sub(lv, rv), nil
+	case STAR:
+		return new(big.Int).Mul(lv, rv), nil
+	case SLASH:
+		if rv.Sign() == 0 {
+			return nil, divisionByZero
+		}
+
+		// An inexact quotient becomes a decimal rather than a float, so the big numbers do not lose their precision.
+		quotient, remainder := new(big.Int).QuoRem(lv, rv, new(big.Int))
+		if remainder.Sign() != 0 {
+			return decimalFromRat(new(big.Rat).SetFrac(lv, rv), 0), nil
+		}
+		return quotient, nil
+	case PERCENT:
+		if rv.Sign() == 0 {
+			return nil, divisionByZero
+		}
+		return new(big.Int).Rem(lv, rv), nil
+	case STAR_STAR:
+		if rv.Sign() < 0 {
+			lf, _ := toF64(lv)
+			rf, _ := toF64(rv)
+			return math.Pow(lf, rf), nil
+		}
+		return new(big.Int).Exp(lv, rv, nil), nil
+	case TILDE_SLASH:
+		if rv.Sign() == 0 {
+			return nil, divisionByZero
+		}
+		return new(big.Int).Quo(lv, rv), nil
+	}
+
+	panic("unknown arithmetic operator")
+}
+
+func decimalArithmetic(operator Token, lv, rv decimal) (any, error) {
+	divisionByZero := RuntimeError{line: operator.Line, message: "Division by zero."}
+
+	switch operator.Type {
+	case PLUS:
+		l, r := align(lv, rv)
+		return decimal{unscaled: new(big.Int).Add(l.unscaled, r.unscaled), scale: l.scale}, nil
+	case MINUS:
+		l, r := align(lv, rv)
+		return decimal{unscaled: new(big.Int).Sub(l.unscaled, r.unscaled), scale: l.scale}, nil
+	case STAR:
+		return decimal{unscaled: new(big.Int).Mul(lv.unscaled, rv.unscaled), scale: lv.scale + rv.scale}, nil
+	case SLASH:
+		if rv.unscaled.Sign() == 0 {
+			return nil, divisionByZero
+		}
+		return decimalFromRat(new(big.Rat).Quo(lv.rat(), rv.rat()), max(lv.scale, rv.scale)), nil
+	case PERCENT:
+		if rv.unscaled.Sign() == 0 {
+			return nil, divisionByZero
+		}
+		l, r := align(lv, rv)
+		return decimal{unscaled: new(big.Int).Rem(l.unscaled, r.unscaled), scale: l.scale}, nil
+	case STAR_STAR:
+		exponent := rv.rescale(0)
+		if rv.cmp(exponent) != 0 || exponent.unscaled.Sign() < 0 || !exponent.unscaled.IsInt64() {
+			return nil, RuntimeError{line: operator.Line, message: "Exponent of a decimal must be a non-negative integer."}
+		}
+
+		n := exponent.unscaled.Int64()
+		return decimal{unscaled: new(big.Int).Exp(lv.unscaled, exponent.unscaled, nil), scale: lv.scale * int(n)}, nil
+	case TILDE_SLASH:
+		if rv.unscaled.Sign() == 0 {
+			return nil, divisionByZero
+		}
+		l, r := align(lv, rv)
+		return decimal{unscaled: new(big.Int).Quo(l.unscaled, r.unscaled), scale: 0}, nil
+	}
+
+	panic("unknown arithmetic operator")
+}
+
+// multiplyInt64 returns false when the product does not fit into int64.
+func multiplyInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+
+	return product, true
+}
+
+// powerOfTrivialBase raises 0, 1 or -1 to the non-negative exponent.
+func powerOfTrivialBase(base, exponent int64) int64 {
+	switch {
+	case exponent == 0:
+		return 1
+	case base == -1 && exponent%2 == 1:
+		return -1
+	case base == -1:
+		return 1
+	default:
+		return base
+	}
+}
+
+// compare compares two numbers, it returns a negative number when left is smaller, zero when they are equal and a positive number otherwise.
+// The second return value is false when the numbers can not be ordered, because one of them is NaN.
+func compare(left, right any) (int, bool) {
+	lv, lok := left.(int64)
+	rv, rok := right.(int64)
+	if lok && rok {
+		return cmpOrdered(lv, rv), true
+	}
+
+	lf, lFloat := left.(float64)
+	rf, rFloat := right.(float64)
+	if (lFloat && rFloat) || (lFloat && !isFinite(lf)) || (rFloat && !isFinite(rf)) {
+		lf, _ = toF64(left)
+		rf, _ = toF64(right)
+		if math.IsNaN(lf) || math.IsNaN(rf) {
+			return 0, false
+		}
+		return cmpOrdered(lf, rf), true
+	}
+
+	// Everything else can be compared exactly as fractions, even a float64 with a *big.Int.
+	return toRat(left).Cmp(toRat(right)), true
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func cmpOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparison evaluates the `<`, `<=`, `>` and `>=` operators.
+func comparison(operator Token, left, right any) (any, error) {
+	if !isNumber(left) || !isNumber(right) {
+		return nil, RuntimeError{line: operator.Line, message: "Operands must be two numbers."}
+	}
+
+	c, ok := compare(left, right)
+	if !ok {
+		return false, nil
+	}
+
+	switch operator.Type {
+	case LESS:
+		return c < 0, nil
+	case LESS_EQUAL:
+		return c <= 0, nil
+	case GREATER:
+		return c > 0, nil
+	case GREATER_EQUAL:
+		return c >= 0, nil
+	}
+
+	panic("unknown comparison operator")
+}
+
+// bitwise evaluates the bitwise operators, the operands must be integers.
+func bitwise(operator Token, left, right any) (any, error) {
+	_, lBig := left.(*big.Int)
+	_, rBig := right.(*big.Int)
+	if lBig || rBig {
+		return bigBitwise(operator, left, right)
+	}
+
+	lv, err := toInt64(left)
+	if err != nil {
+		return nil, RuntimeError{line: operator.Line, message: "Operands must be two integers."}
+	}
+	rv, err := toInt64(right)
+	if err != nil {
+		return nil, RuntimeError{line: operator.Line, message: "Operands must be two integers."}
+	}
+
+	switch operator.Type {
+	case AMPERSAND:
+		return lv & rv, nil
+	case PIPE:
+		return lv | rv, nil
+	case CARET:
+		return lv ^ rv, nil
+	}
+
+	if rv < 0 {
+		return nil, RuntimeError{line: operator.Line, message: "Shift count must not be negative."}
+	}
+	if operator.Type == LESS_LESS {
+		// The shift overflows when it can not be undone, because some of the bits, or the sign, were shifted out.
+		shifted := lv << rv
+		if lv != 0 && (rv >= 64 || shifted>>rv != lv) {
+			return nil, RuntimeError{line: operator.Line, message: "Integer overflow."}
+		}
+		return shifted, nil
+	}
+	return lv >> rv, nil
+}
+
+func bigBitwise(operator Token, left, right any) (any, error) {
+	lv, lok := toBigIntegral(left)
+	rv, rok := toBigIntegral(right)
+	if !lok || !rok {
+		return nil, RuntimeError{line: operator.Line, message: "Operands must be two integers."}
+	}
+
+	switch operator.Type {
+	case AMPERSAND:
+		return new(big.Int).And(lv, rv), nil
+	case PIPE:
+		return new(big.Int).Or(lv, rv), nil
+	case CARET:
+		return new(big.Int).Xor(lv, rv), nil
+	}
+
+	if rv.Sign() < 0 {
+		return nil, RuntimeError{line: operator.Line, message: "Shift count must not be negative."}
+	}
+	if !rv.IsUint64() || rv.Uint64() > math.MaxUint32 {
+		return nil, RuntimeError{line: operator.Line, message: "Shift count is too large."}
+	}
+	if operator.Type == LESS_LESS {
+		return new(big.Int).Lsh(lv, uint(rv.Uint64())), nil
+	}
+	return new(big.Int).Rsh(lv, uint(rv.Uint64())), nil
+}
+
+func negate(operator Token, v any) (any, error) {
+	switch v := v.(type) {
+	case int64:
+		if v == math.MinInt64 {
+			return nil, RuntimeError{line: operator.Line, message: "Integer overflow."}
+		}
+		return -v, nil
+	case float64:
+		return -v, nil
+	case *big.Int:
+		return new(big.Int).Neg(v), nil
+	case decimal:
+		return decimal{unscaled: new(big.Int).Neg(v.unscaled), scale: v.scale}, nil
+	default:
+		return nil, RuntimeError{line: operator.Line, message: "Operand must be a number."}
+	}
+}
+
+func complement(operator Token, v any) (any, error) {
+	if b, ok := v.(*big.Int); ok {
+		return new(big.Int).Not(b), nil
+	}
+
+	i, err := toInt64(v)
+	if err != nil {
+		return nil, RuntimeError{line: operator.Line, message: "Operand must be an integer."}
+	}
+
+	return ^i, nil
+}
+
+// toInt64 converts the number to an integer for the bitwise operators.
+// Numbers with a fractional part or out of the int64 range are not integers.
+func toInt64(v any) (int64, error) {
+	if i, ok := v.(int64); ok {
+		return i, nil
+	}
+
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("value %v is not an integer", v)
+	}
+
+	return int64(f), nil
+}
+
+// toBigIntegral converts the integral number to *big.Int, it returns false for the numbers with a fractional part.
+func toBigIntegral(v any) (*big.Int, bool) {
+	switch v := v.(type) {
+	case *big.Int:
+		return v, true
+	case int64:
+		return big.NewInt(v), true
+	case float64:
+		i, err := toInt64(v)
+		return big.NewInt(i), err == nil
+	default:
+		return nil, false
+	}
+}
+
+// toBigInt promotes an int64 to *big.Int, the value must be one of them.
+func toBigInt(v any) *big.Int {
+	if i, ok := v.(int64); ok {
+		return big.NewInt(i)
+	}
+
+	return v.(*big.Int)
+}
+
+// toDecimal promotes an integer to decimal, the value must not be a float64.
+func toDecimal(v any) decimal {
+	switch v := v.(type) {
+	case decimal:
+		return v
+	case int64:
+		return decimalFromInt(big.NewInt(v))
+	default:
+		return decimalFromInt(v.(*big.Int))
+	}
+}
+
+// toRat converts the number into an exact fraction, floats must be finite.
+func toRat(v any) *big.Rat {
+	switch v := v.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(v)
+	case *big.Int:
+		return new(big.Rat).SetInt(v)
+	case decimal:
+		return v.rat()
+	default:
+		return new(big.Rat).SetFloat64(v.(float64))
+	}
+}
@@ -14,32 +14,93 @@ func (se SyntaxError) Error() string {
 	return fmt.Sprintf("[line %v] %v", se.line, se.message)
 }
 
+// Parse parses the whole program.
+// If the source has lexical errors, the first one is returned as an UnexpectedTokenError, since parsing without the tokens that could not be scanned would only report a misleading error.
 func (l *Lox) Parse(r io.Reader) ([]Statement, error) {
 	result, err := l.Tokenize(r)
 	if err != nil {
 		return nil, err
 	}
+	if len(result.Errors) > 0 {
+		return nil, result.Errors[0]
+	}
+
 	parser := newParser(result.Tokens)
 	return parser.parse()
 }
 
+// ParseExpression parses a single expression, the lexical errors are returned the same way as in `Parse`.
 func (l *Lox) ParseExpression(r io.Reader) (Expression, error) {
 	result, err := l.Tokenize(r)
 	if err != nil {
 		return nil, err
 	}
+	if len(result.Errors) > 0 {
+		return nil, result.Errors[0]
+	}
 
 	parser := newParser(result.Tokens)
 	return parser.parseExpression()
 }
 
+// nextStatementTokens reads the tokens of the next top-level statement.
+// The returned tokens always end with the EOF token, so they can be handed to the parser as they are.
+func nextStatementTokens(s *Scanner) ([]Token, error) {
+	var tokens []Token
+	depth := 0
+	// Where the block that ends the statement starts, like the body of `for (x in items) { ... }` which is not followed by a semicolon.
+	blockStart := -1
+
+	for {
+		// Same as in the `Parse`, the lexical errors are returned as they are.
+		t, err := s.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Type == EOF {
+			return append(tokens, t), nil
+		}
+
+		tokens = append(tokens, t)
+		switch t.Type {
+		case LEFT_BRACE:
+			if depth == 0 {
+				blockStart = -1
+				if len(tokens) == 1 || tokens[len(tokens)-2].Type == RIGHT_PAREN {
+					blockStart = len(tokens) - 1
+				}
+			}
+			depth += 1
+		case RIGHT_BRACE:
+			depth -= 1
+			if depth == 0 && blockStart >= 0 && !newParser(tokens[blockStart:]).startsMapLiteral() {
+				return append(tokens, newToken(EOF, t.Line)), nil
+			}
+		case LEFT_PAREN, LEFT_BRACKET:
+			depth += 1
+		case RIGHT_PAREN, RIGHT_BRACKET:
+			depth -= 1
+		case SEMICOLON:
+			if depth <= 0 {
+				return append(tokens, newToken(EOF, t.Line)), nil
+			}
+		}
+	}
+}
+
 type parser struct {
-	tokens  []token
+	tokens  []Token
 	current int
+	// How many loops the parser is currently inside of, `break` and `continue` are only valid when it is positive.
+	// A function body has to reset it, so that the statements do not jump out of the function.
+	loops int
+	// The first and the last token of every parsed statement, the formatter keeps the comments around them.
+	bounds map[Statement][2]Token
 }
 
-func newParser(tokens []token) *parser {
-	return &parser{tokens: tokens, current: 0}
+func newParser(tokens []Token) *parser {
+	return &parser{tokens: tokens, current: 0, bounds: map[Statement][2]Token{}}
 }
 
 func (p *parser) parseExpression() (Expression, error) {
@@ -50,6 +111,10 @@ func (p *parser) parseExpression() (Expression, error) {
 		return nil, err
 	}
 
+	if !p.isAtEnd() {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect end of expression."}
+	}
+
 	return expr, nil
 }
 
@@ -70,9 +135,35 @@ func (p *parser) parse() ([]Statement, error) {
 }
 
 func (p *parser) statement() (Statement, error) {
+	first := p.peek()
+	statement, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	p.bounds[statement] = [2]Token{first, p.previous()}
+	return statement, nil
+}
+
+func (p *parser) parseStatement() (Statement, error) {
+	if p.match(BREAK, CONTINUE) {
+		return p.jumpStatement()
+	}
+
+	// A statement that starts with a brace is a block, unless it is clearly a map literal.
+	if p.check(LEFT_BRACE) && !p.startsMapLiteral() {
+		p.advance()
+		return p.block()
+	}
+
+	if p.match(FOR) {
+		return p.forInStatement()
+	}
+
 	if !p.match(PRINT) {
 		return p.expressionStatement()
 	}
+	keyword := p.previous()
 
 	expr, err := p.expression()
 	if err != nil {
@@ -81,11 +172,100 @@ func (p *parser) statement() (Statement, error) {
 
 	if p.match(SEMICOLON) {
 		return &printStatement{
-			expr: expr,
+			expr:      expr,
+			keyword:   keyword,
+			semicolon: p.previous(),
 		}, nil
 	}
 
-	return nil, SyntaxError{line: 1, message: "Expect ';' after value."}
+	return nil, SyntaxError{line: p.previous().Line, message: "Expect ';' after value."}
+}
+
+// block parses the rest of the block, the left brace has already been consumed.
+func (p *parser) block() (Statement, error) {
+	leftBrace := p.previous()
+
+	var statements []Statement
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		statement, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+
+		statements = append(statements, statement)
+	}
+
+	if !p.match(RIGHT_BRACE) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect '}' after block."}
+	}
+
+	return &blockStatement{statements: statements, leftBrace: leftBrace, rightBrace: p.previous()}, nil
+}
+
+// forInStatement parses the rest of the `for-in` loop, the `for` keyword has already been consumed.
+func (p *parser) forInStatement() (Statement, error) {
+	keyword := p.previous()
+	if !p.match(LEFT_PAREN) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect '(' after 'for'."}
+	}
+
+	var variables []Token
+	for {
+		if !p.match(IDENTIFIER) {
+			return nil, SyntaxError{line: p.peek().Line, message: "Expect variable name."}
+		}
+
+		name := p.previous()
+		if len(variables) > 0 && *variables[0].Lexeme == *name.Lexeme {
+			return nil, SyntaxError{line: name.Line, message: fmt.Sprintf("Error at '%s': Already a variable with this name in this scope.", *name.Lexeme)}
+		}
+		variables = append(variables, name)
+
+		if len(variables) == 2 || !p.match(COMMA) {
+			break
+		}
+	}
+
+	if !p.match(IN) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect 'in' after loop variables."}
+	}
+	in := p.previous()
+
+	iterable, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(RIGHT_PAREN) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect ')' after for clauses."}
+	}
+
+	p.loops++
+	body, err := p.statement()
+	p.loops--
+	if err != nil {
+		return nil, err
+	}
+
+	return &forInStatement{keyword: keyword, variables: variables, in: in, iterable: iterable, body: body}, nil
+}
+
+// jumpStatement parses the rest of the `break` or `continue` statement, the keyword has already been consumed.
+func (p *parser) jumpStatement() (Statement, error) {
+	keyword := p.previous()
+	if p.loops == 0 {
+		return nil, SyntaxError{line: keyword.Line, message: fmt.Sprintf("Error at '%s': Can't use '%s' outside of a loop.", *keyword.Lexeme, *keyword.Lexeme)}
+	}
+
+	if !p.match(SEMICOLON) {
+		return nil, SyntaxError{line: keyword.Line, message: fmt.Sprintf("Expect ';' after '%s'.", *keyword.Lexeme)}
+	}
+
+	if keyword.Type == BREAK {
+		return &breakStatement{keyword: keyword, semicolon: p.previous()}, nil
+	}
+
+	return &continueStatement{keyword: keyword, semicolon: p.previous()}, nil
 }
 
 func (p *parser) expressionStatement() (Statement, error) {
@@ -95,14 +275,115 @@ func (p *parser) expressionStatement() (Statement, error) {
 	}
 
 	if p.match(SEMICOLON) {
-		return &exprStatement{expr: expr}, nil
+		return &exprStatement{expr: expr, semicolon: p.previous()}, nil
 	}
 
-	return nil, SyntaxError{line: 1, message: "Expect ';' after expression."}
+	return nil, SyntaxError{line: p.previous().Line, message: "Expect ';' after expression."}
 }
 
 func (p *parser) expression() (Expression, error) {
-	return p.equality()
+	return p.assignment()
+}
+
+func (p *parser) assignment() (Expression, error) {
+	expr, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(EQUAL) {
+		equals := p.previous()
+		value, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAssignmentTarget(expr) {
+			return nil, invalidAssignmentTarget(equals)
+		}
+
+		return &assignExpression{Target: expr, Equals: equals, Value: value}, nil
+	}
+
+	if p.match(PLUS_EQUAL, MINUS_EQUAL, STAR_EQUAL, SLASH_EQUAL) {
+		operator := p.previous()
+		value, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAssignmentTarget(expr) {
+			return nil, invalidAssignmentTarget(operator)
+		}
+
+		return &compoundAssignExpression{Target: expr, Operator: operator, Value: value}, nil
+	}
+
+	return expr, nil
+}
+
+// conditional is right-associative, so `a ? b : c ? d : e` is `a ? b : (c ? d : e)`.
+func (p *parser) conditional() (Expression, error) {
+	condition, err := p.coalesce()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(QUESTION) {
+		return condition, nil
+	}
+
+	thenBranch, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(COLON) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect ':' after the then branch of the conditional expression."}
+	}
+
+	elseBranch, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+
+	return &conditionalExpression{Condition: condition, Then: thenBranch, Else: elseBranch}, nil
+}
+
+func (p *parser) coalesce() (Expression, error) {
+	expr, err := p.equality()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(QUESTION_QUESTION) {
+		operator := p.previous()
+		right, err := p.equality()
+		if err != nil {
+			return nil, err
+		}
+		expr = &coalesceExpression{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+// isAssignmentTarget reports whether the expression can be assigned to, like the `count` in `count += 1`.
+func isAssignmentTarget(expr Expression) bool {
+	switch expr := expr.(type) {
+	case *variableExpression:
+		return true
+	case *getExpression:
+		return !expr.Optional
+	case *indexExpression:
+		return !expr.Optional
+	default:
+		return false
+	}
+}
+
+func invalidAssignmentTarget(operator Token) SyntaxError {
+	return SyntaxError{line: operator.Line, message: fmt.Sprintf("Error at '%s': Invalid assignment target.", *operator.Lexeme)}
 }
 
 func (p *parser) equality() (Expression, error) {
@@ -125,12 +406,84 @@ func (p *parser) equality() (Expression, error) {
 }
 
 func (p *parser) comparison() (Expression, error) {
-	expr, err := p.term()
+	expr, err := p.bitOr()
 	if err != nil {
 		return nil, err
 	}
 
 	for p.match(GREATER, GREATER_EQUAL, LESS, LESS_EQUAL) {
+		operator := p.previous()
+		right, err := p.bitOr()
+		if err != nil {
+			return nil, err
+		}
+		expr = &binaryExpression{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func (p *parser) bitOr() (Expression, error) {
+	expr, err := p.bitXor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(PIPE) {
+		operator := p.previous()
+		right, err := p.bitXor()
+		if err != nil {
+			return nil, err
+		}
+		expr = &binaryExpression{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func (p *parser) bitXor() (Expression, error) {
+	expr, err := p.bitAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(CARET) {
+		operator := p.previous()
+		right, err := p.bitAnd()
+		if err != nil {
+			return nil, err
+		}
+		expr = &binaryExpression{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func (p *parser) bitAnd() (Expression, error) {
+	expr, err := p.shift()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(AMPERSAND) {
+		operator := p.previous()
+		right, err := p.shift()
+		if err != nil {
+			return nil, err
+		}
+		expr = &binaryExpression{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func (p *parser) shift() (Expression, error) {
+	expr, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(LESS_LESS, GREATER_GREATER) {
 		operator := p.previous()
 		right, err := p.term()
 		if err != nil {
@@ -166,7 +519,7 @@ func (p *parser) factor() (Expression, error) {
 		return nil, err
 	}
 
-	for p.match(SLASH, STAR) {
+	for p.match(SLASH, STAR, PERCENT, TILDE_SLASH) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -179,7 +532,21 @@ func (p *parser) factor() (Expression, error) {
 }
 
 func (p *parser) unary() (Expression, error) {
-	if p.match(BANG, MINUS) {
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		operator := p.previous()
+		target, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAssignmentTarget(target) {
+			return nil, invalidAssignmentTarget(operator)
+		}
+
+		return &updateExpression{Target: target, Operator: operator, Prefix: true}, nil
+	}
+
+	if p.match(BANG, MINUS, TILDE) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -188,43 +555,295 @@ func (p *parser) unary() (Expression, error) {
 		return &unaryExpression{Operator: operator, Right: right}, nil
 	}
 
-	return p.primary()
+	return p.power()
+}
+
+// power binds tighter than the unary operators, so `-2 ** 2` is `-(2 ** 2)`.
+// It is right-associative, the exponent is parsed with `unary` so that `2 ** -1` works too.
+func (p *parser) power() (Expression, error) {
+	expr, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(STAR_STAR) {
+		operator := p.previous()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryExpression{Left: expr, Operator: operator, Right: right}, nil
+	}
+
+	return expr, nil
+}
+
+func (p *parser) postfix() (Expression, error) {
+	expr, err := p.call()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		operator := p.previous()
+		if !isAssignmentTarget(expr) {
+			return nil, invalidAssignmentTarget(operator)
+		}
+
+		return &updateExpression{Target: expr, Operator: operator, Prefix: false}, nil
+	}
+
+	return expr, nil
+}
+
+// call parses the calls and the property accesses that follow the primary expression, like `config?.server.port()`.
+// If any of them is optional, the whole chain is wrapped in an optionalChainExpression, which is where the chain short-circuits to.
+func (p *parser) call() (Expression, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	optional := false
+	for {
+		if p.match(LEFT_PAREN) {
+			expr, err = p.finishCall(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(DOT) {
+			if !p.match(IDENTIFIER) {
+				return nil, SyntaxError{line: p.peek().Line, message: "Expect property name after '.'."}
+			}
+
+			expr = &getExpression{Object: expr, Name: p.previous()}
+		} else if p.match(LEFT_BRACKET) {
+			expr, err = p.finishIndex(expr, false)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(QUESTION_DOT) {
+			optional = true
+
+			if p.match(LEFT_BRACKET) {
+				expr, err = p.finishIndex(expr, true)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if !p.match(IDENTIFIER) {
+				return nil, SyntaxError{line: p.peek().Line, message: "Expect property name after '?.'."}
+			}
+
+			expr = &getExpression{Object: expr, Name: p.previous(), Optional: true}
+		} else {
+			break
+		}
+	}
+
+	if optional {
+		return &optionalChainExpression{Expression: expr}, nil
+	}
+
+	return expr, nil
+}
+
+func (p *parser) finishCall(callee Expression) (Expression, error) {
+	var arguments []Expression
+	if !p.check(RIGHT_PAREN) {
+		for {
+			argument, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
+	if !p.match(RIGHT_PAREN) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect ')' after arguments."}
+	}
+
+	return &callExpression{Callee: callee, Arguments: arguments, Paren: p.previous()}, nil
+}
+
+func (p *parser) finishIndex(object Expression, optional bool) (Expression, error) {
+	index, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(RIGHT_BRACKET) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect ']' after index."}
+	}
+
+	return &indexExpression{Object: object, Index: index, Bracket: p.previous(), Optional: optional}, nil
 }
 
 func (p *parser) primary() (Expression, error) {
 	if p.match(FALSE) {
-		return &literalExpression{Value: false}, nil
+		return &literalExpression{Value: false, Token: p.previous()}, nil
 	}
 
 	if p.match(TRUE) {
-		return &literalExpression{Value: true}, nil
+		return &literalExpression{Value: true, Token: p.previous()}, nil
 	}
 
 	if p.match(NIL) {
-		return &literalExpression{Value: nil}, nil
+		return &literalExpression{Value: nil, Token: p.previous()}, nil
 	}
 
 	if p.match(NUMBER, STRING) {
-		return &literalExpression{Value: p.previous().Literal}, nil
+		return &literalExpression{Value: p.previous().Literal, Token: p.previous()}, nil
+	}
+
+	if p.match(INTERPOLATION) {
+		return p.interpolation()
+	}
+
+	if p.match(IDENTIFIER) {
+		return &variableExpression{Name: p.previous()}, nil
+	}
+
+	if p.match(LEFT_BRACKET) {
+		return p.list()
+	}
+
+	if p.match(LEFT_BRACE) {
+		return p.mapLiteral()
 	}
 
 	if p.match(LEFT_PAREN) {
+		leftParen := p.previous()
 		expr, err := p.expression()
 		if err != nil {
-			return nil, SyntaxError{line: 1, message: "Error at ')': Expect expression."}
+			return nil, SyntaxError{line: p.peek().Line, message: "Error at ')': Expect expression."}
 		}
 
 		if p.match(RIGHT_PAREN) {
-			return &groupingExpression{Expression: expr}, nil
+			return &groupingExpression{Expression: expr, LeftParen: leftParen, RightParen: p.previous()}, nil
 		}
 
-		return nil, SyntaxError{line: 1, message: "Error at ')': Expect expression."}
+		return nil, SyntaxError{line: p.peek().Line, message: "Error at ')': Expect expression."}
 	}
 
-	return nil, SyntaxError{line: 1, message: "Expect expression."}
+	return nil, SyntaxError{line: p.peek().Line, message: "Expect expression."}
+}
+
+// list parses the rest of the list literal, the left bracket has already been consumed.
+// A trailing comma is allowed, so the elements can be written one per line.
+func (p *parser) list() (Expression, error) {
+	leftBracket := p.previous()
+
+	var elements []Expression
+	for !p.check(RIGHT_BRACKET) {
+		element, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+
+		if !p.match(COMMA) {
+			break
+		}
+	}
+
+	if !p.match(RIGHT_BRACKET) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect ']' after list elements."}
+	}
+
+	return &listExpression{Elements: elements, LeftBracket: leftBracket, RightBracket: p.previous()}, nil
+}
+
+// startsMapLiteral checks whether the brace is followed by a simple key and a colon, like in `{"a": 1}`, which a block can never start with.
+func (p *parser) startsMapLiteral() bool {
+	if p.current+2 >= len(p.tokens) {
+		return false
+	}
+
+	key, colon := p.tokens[p.current+1], p.tokens[p.current+2]
+	switch key.Type {
+	case STRING, NUMBER, IDENTIFIER, TRUE, FALSE, NIL:
+		return colon.Type == COLON
+	default:
+		return false
+	}
+}
+
+// mapLiteral parses the rest of the map literal, the left brace has already been consumed.
+// Same as the lists, a trailing comma is allowed.
+func (p *parser) mapLiteral() (Expression, error) {
+	leftBrace := p.previous()
+
+	var keys, values []Expression
+	for !p.check(RIGHT_BRACE) {
+		var key Expression
+		if p.check(IDENTIFIER) && p.tokens[p.current+1].Type == COLON {
+			name := p.advance()
+			key = &literalExpression{Value: *name.Lexeme, Token: name}
+		} else {
+			var err error
+			key, err = p.expression()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !p.match(COLON) {
+			return nil, SyntaxError{line: p.peek().Line, message: "Expect ':' after map key."}
+		}
+
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+
+		if !p.match(COMMA) {
+			break
+		}
+	}
+
+	if !p.match(RIGHT_BRACE) {
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect '}' after map entries."}
+	}
+
+	return &mapExpression{Keys: keys, Values: values, LeftBrace: leftBrace, RightBrace: p.previous()}, nil
+}
+
+// interpolation parses the rest of the interpolated string, the first INTERPOLATION token has already been consumed.
+func (p *parser) interpolation() (Expression, error) {
+	parts := []Expression{&literalExpression{Value: p.previous().Literal, Token: p.previous()}}
+
+	for {
+		expr, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, expr)
+
+		if p.match(INTERPOLATION) {
+			parts = append(parts, &literalExpression{Value: p.previous().Literal, Token: p.previous()})
+			continue
+		}
+
+		if p.match(STRING) {
+			parts = append(parts, &literalExpression{Value: p.previous().Literal, Token: p.previous()})
+			return &interpolationExpression{Parts: parts}, nil
+		}
+
+		return nil, SyntaxError{line: p.peek().Line, message: "Expect '}' after interpolated expression."}
+	}
 }
 
-func (p *parser) match(tokenTypes ...tokenType) bool {
+func (p *parser) match(tokenTypes ...TokenType) bool {
 	for _, tokenType := range tokenTypes {
 		if p.check(tokenType) {
 			p.advance()
@@ -239,7 +858,7 @@ func (p *parser) isAtEnd() bool {
 	return p.peek().Type == EOF
 }
 
-func (p *parser) check(tokenType tokenType) bool {
+func (p *parser) check(tokenType TokenType) bool {
 	if p.isAtEnd() {
 		return false
 	}
@@ -247,15 +866,15 @@ func (p *parser) check(tokenType tokenType) bool {
 	return p.peek().Type == tokenType
 }
 
-func (p *parser) peek() token {
+func (p *parser) peek() Token {
 	return p.tokens[p.current]
 }
 
-func (p *parser) previous() token {
+func (p *parser) previous() Token {
 	return p.tokens[p.current-1]
 }
 
-func (p *parser) advance() token {
+func (p *parser) advance() Token {
 	if !p.isAtEnd() {
 		p.current += 1
 	}
@@ -2,6 +2,11 @@ package lox_test
 
 import (
 	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
@@ -33,6 +38,26 @@ func TestParseExpression(t *testing.T) {
 			expectedOut: "(group 12.87)",
 			expectedErr: "",
 		},
+		{
+			input:       "\"a ${1 + 2} b ${\"c\"}\"",
+			expectedOut: "(interpolate a  (+ 1.0 2.0)  b  c )",
+			expectedErr: "",
+		},
+		{
+			input:       "\"a ${1 + 2 \"",
+			expectedOut: "",
+			expectedErr: "[line 1] Error: Unterminated string.\n",
+		},
+		{
+			input:       "\"a ${1 + 2 3}\"",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect '}' after interpolated expression.",
+		},
+		{
+			input:       "1 + 9223372036854775808",
+			expectedOut: "",
+			expectedErr: "[line 1] Error: Integer literal is out of range: 9223372036854775808.\n",
+		},
 		{
 			input:       "!true",
 			expectedOut: "(! true)",
@@ -53,6 +78,116 @@ func TestParseExpression(t *testing.T) {
 			expectedOut: "",
 			expectedErr: "[line 1] Error at ')': Expect expression.",
 		},
+		{
+			input:       "1 | 2 ^ 3 & 4 << 5 + 6 % 7 ~/ 8",
+			expectedOut: "(| 1.0 (^ 2.0 (& 3.0 (<< 4.0 (+ 5.0 (~/ (% 6.0 7.0) 8.0))))))",
+			expectedErr: "",
+		},
+		{
+			input:       "-2 ** 3 ** ~1",
+			expectedOut: "(- (** 2.0 (** 3.0 (~ 1.0))))",
+			expectedErr: "",
+		},
+		{
+			input:       "decimal(\"1.10\") * 2n + 1.5d",
+			expectedOut: "(+ (* (call decimal 1.10) 2n) 1.5d)",
+			expectedErr: "",
+		},
+		{
+			input:       "decimal(1",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect ')' after arguments.",
+		},
+		{
+			input:       "a += b -= 2 * c++",
+			expectedOut: "(+= a (-= b (* 2.0 (post++ c))))",
+			expectedErr: "",
+		},
+		{
+			input:       "-++a / --b",
+			expectedOut: "(/ (- (pre++ a)) (pre-- b))",
+			expectedErr: "",
+		},
+		{
+			input:       "1 += 2",
+			expectedOut: "",
+			expectedErr: "[line 1] Error at '+=': Invalid assignment target.",
+		},
+		{
+			input:       "--1",
+			expectedOut: "",
+			expectedErr: "[line 1] Error at '--': Invalid assignment target.",
+		},
+		{
+			input:       "(a)++",
+			expectedOut: "",
+			expectedErr: "[line 1] Error at '++': Invalid assignment target.",
+		},
+		{
+			input:       "a ? b : c ? d : e",
+			expectedOut: "(?: a b (?: c d e))",
+			expectedErr: "",
+		},
+		{
+			input:       "a ?? b ?? c == d ? 1 + 2 : 3",
+			expectedOut: "(?: (?? (?? a b) (== c d)) (+ 1.0 2.0) 3.0)",
+			expectedErr: "",
+		},
+		{
+			input:       "true ? 1",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect ':' after the then branch of the conditional expression.",
+		},
+		{
+			input:       "config?.server.port()",
+			expectedOut: "(call (. (?. config server) port))",
+			expectedErr: "",
+		},
+		{
+			input:       "items?.[1 + 2]",
+			expectedOut: "(?.[] items (+ 1.0 2.0))",
+			expectedErr: "",
+		},
+		{
+			input:       "a?.b += 1",
+			expectedOut: "",
+			expectedErr: "[line 1] Error at '+=': Invalid assignment target.",
+		},
+		{
+			input:       "a?.",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect property name after '?.'.",
+		},
+		{
+			input:       "items[-1] = [1, [], items?.[0],]",
+			expectedOut: "(= ([] items (- 1.0)) (list 1.0 (list) (?.[] items 0.0)))",
+			expectedErr: "",
+		},
+		{
+			input:       "[1, 2",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect ']' after list elements.",
+		},
+		{
+			input:       "items?.[0] = 1",
+			expectedOut: "",
+			expectedErr: "[line 1] Error at '=': Invalid assignment target.",
+		},
+		{
+			input:       "{a: 1, \"b\": [], 1 + 2: {},}",
+			expectedOut: "(map a 1.0 b (list) (+ 1.0 2.0) (map))",
+			expectedErr: "",
+		},
+		{
+			input:       "{a 1}",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect ':' after map key.",
+		},
+		{
+			input:       "1 2",
+			expectedOut: "",
+			expectedErr: "[line 1] Expect end of expression.",
+		},
 		{
 			input:       "\"baz\"!=\"world\"",
 			expectedOut: "(!= baz world)",
@@ -77,7 +212,7 @@ func TestParseExpression(t *testing.T) {
 				}
 
 				if err.Error() != tt.expectedErr {
-					t.Errorf("\nexpected error:\n%q\ngot:\n%q\n", tt.expectedOut, err.Error())
+					t.Errorf("\nexpected error:\n%q\ngot:\n%q\n", tt.expectedErr, err.Error())
 				}
 			}
 
@@ -108,3 +243,57 @@ func TestParseExpression(t *testing.T) {
 	}
 
 }
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestParseGolden prints every program in testdata/parse and compares it with the .golden file next to it.
+// The programs that do not parse have their syntax error in the .golden file instead.
+// Run `go test ./app/lox -run TestParseGolden -update` to regenerate the golden files.
+func TestParseGolden(t *testing.T) {
+	sources, err := filepath.Glob("testdata/parse/*.lox")
+	if err != nil {
+		t.Fatalf("failed to list the sources: %v", err)
+	}
+
+	for _, source := range sources {
+		t.Run(filepath.Base(source), func(t *testing.T) {
+			content, err := os.ReadFile(source)
+			if err != nil {
+				t.Fatalf("failed to read source: %v", err)
+			}
+
+			l := lox.NewLox()
+			var out string
+
+			statements, err := l.Parse(bytes.NewReader(content))
+			if err == nil {
+				out, err = lox.Format(statements)
+			} else if expr, exprErr := l.ParseExpression(bytes.NewReader(content)); exprErr == nil {
+				// Same as the `parse` command, a single expression is printed on its own.
+				out, err = lox.FormatExpression(expr)
+			} else if errors.As(err, &lox.SyntaxError{}) {
+				// Same as the `parse` command, the error of the whole program is the one reported.
+				out, err = err.Error()+"\n", nil
+			}
+			if err != nil {
+				t.Fatalf("did not expect error, but got: %v", err)
+			}
+
+			golden := strings.TrimSuffix(source, ".lox") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(out), 0o644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+			}
+
+			expectedOut, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+
+			if out != string(expectedOut) {
+				t.Errorf("\nexpected output:\n%q\ngot:\n%q\n", string(expectedOut), out)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package lox
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
 
 type printer struct{}
 
+// Format prints every statement as an S-expression on its own line.
 func Format(statements []Statement) (string, error) {
 	result := ""
 	printer := &printer{}
@@ -14,7 +19,7 @@ func Format(statements []Statement) (string, error) {
 			return "", fmt.Errorf("failed to format statements: %w", err)
 		}
 
-		result += fmt.Sprintf("%v", out)
+		result += fmt.Sprintf("%v\n", out)
 	}
 
 	return result, nil
@@ -34,7 +39,48 @@ func (p *printer) visitExprStatement(statement *exprStatement) (any, error) {
 }
 
 func (p *printer) visitPrintStatement(statement *printStatement) (any, error) {
-	return parenthesize(";", p, statement.expr)
+	return parenthesize("print", p, statement.expr)
+}
+
+func (p *printer) visitBreakStatement(statement *breakStatement) (any, error) {
+	return "(break)", nil
+}
+
+func (p *printer) visitContinueStatement(statement *continueStatement) (any, error) {
+	return "(continue)", nil
+}
+
+func (p *printer) visitBlockStatement(statement *blockStatement) (any, error) {
+	result := "(block"
+	for _, s := range statement.statements {
+		out, err := s.accept(p)
+		if err != nil {
+			return nil, err
+		}
+
+		result += fmt.Sprintf(" %v", out)
+	}
+
+	return result + ")", nil
+}
+
+// visitForInStatement prints the loop variables in their own parens, like `(for-in (k v) items (print v))`.
+func (p *printer) visitForInStatement(statement *forInStatement) (any, error) {
+	var variables []string
+	for _, variable := range statement.variables {
+		variables = append(variables, *variable.Lexeme)
+	}
+
+	iterable, err := statement.iterable.accept(p)
+	if err != nil {
+		return nil, err
+	}
+	body, err := statement.body.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("(for-in (%s) %v %v)", strings.Join(variables, " "), iterable, body), nil
 }
 
 func (p *printer) visitBinaryExpression(expr *binaryExpression) (any, error) {
@@ -55,6 +101,18 @@ func (p *printer) visitGroupingExpression(expr *groupingExpression) (any, error)
 
 func (p *printer) visitLiteralExpression(expr *literalExpression) (any, error) {
 	switch n := expr.Value.(type) {
+	case int64:
+		{
+			return fmt.Sprintf("%d.0", n), nil
+		}
+	case *big.Int:
+		{
+			return fmt.Sprintf("%vn", n), nil
+		}
+	case decimal:
+		{
+			return fmt.Sprintf("%vd", n), nil
+		}
 	case float64:
 		{
 			if n == float64(int(n)) {
@@ -72,6 +130,83 @@ func (p *printer) visitLiteralExpression(expr *literalExpression) (any, error) {
 	}
 }
 
+func (p *printer) visitInterpolationExpression(expr *interpolationExpression) (any, error) {
+	return parenthesize("interpolate", p, expr.Parts...)
+}
+
+func (p *printer) visitVariableExpression(expr *variableExpression) (any, error) {
+	return *expr.Name.Lexeme, nil
+}
+
+func (p *printer) visitCallExpression(expr *callExpression) (any, error) {
+	return parenthesize("call", p, append([]Expression{expr.Callee}, expr.Arguments...)...)
+}
+
+func (p *printer) visitCompoundAssignExpression(expr *compoundAssignExpression) (any, error) {
+	return parenthesize(*expr.Operator.Lexeme, p, expr.Target, expr.Value)
+}
+
+func (p *printer) visitUpdateExpression(expr *updateExpression) (any, error) {
+	if expr.Prefix {
+		return parenthesize("pre"+*expr.Operator.Lexeme, p, expr.Target)
+	}
+
+	return parenthesize("post"+*expr.Operator.Lexeme, p, expr.Target)
+}
+
+func (p *printer) visitConditionalExpression(expr *conditionalExpression) (any, error) {
+	return parenthesize("?:", p, expr.Condition, expr.Then, expr.Else)
+}
+
+func (p *printer) visitCoalesceExpression(expr *coalesceExpression) (any, error) {
+	return parenthesize(*expr.Operator.Lexeme, p, expr.Left, expr.Right)
+}
+
+func (p *printer) visitGetExpression(expr *getExpression) (any, error) {
+	object, err := expr.Object.accept(p)
+	if err != nil {
+		return nil, err
+	}
+
+	operator := tokenLexemes[DOT]
+	if expr.Optional {
+		operator = tokenLexemes[QUESTION_DOT]
+	}
+
+	return fmt.Sprintf("(%s %v %s)", operator, object, *expr.Name.Lexeme), nil
+}
+
+func (p *printer) visitIndexExpression(expr *indexExpression) (any, error) {
+	if expr.Optional {
+		return parenthesize("?.[]", p, expr.Object, expr.Index)
+	}
+
+	return parenthesize("[]", p, expr.Object, expr.Index)
+}
+
+func (p *printer) visitListExpression(expr *listExpression) (any, error) {
+	return parenthesize("list", p, expr.Elements...)
+}
+
+func (p *printer) visitAssignExpression(expr *assignExpression) (any, error) {
+	return parenthesize("=", p, expr.Target, expr.Value)
+}
+
+// visitMapExpression prints the keys and the values in pairs, like `(map a 1.0 b 2.0)`.
+func (p *printer) visitMapExpression(expr *mapExpression) (any, error) {
+	var entries []Expression
+	for i := range expr.Keys {
+		entries = append(entries, expr.Keys[i], expr.Values[i])
+	}
+
+	return parenthesize("map", p, entries...)
+}
+
+// The optional chain is not printed, the optional links already show where the chain can short-circuit.
+func (p *printer) visitOptionalChainExpression(expr *optionalChainExpression) (any, error) {
+	return expr.Expression.accept(p)
+}
+
 func (p *printer) visitUnaryExpression(expr *unaryExpression) (any, error) {
 	right, err := expr.Right.accept(p)
 	if err != nil {
@@ -7,14 +7,21 @@ type Statement interface {
 type statementVisitor interface {
 	visitPrintStatement(ps *printStatement) (any, error)
 	visitExprStatement(ps *exprStatement) (any, error)
+	visitBreakStatement(bs *breakStatement) (any, error)
+	visitContinueStatement(cs *continueStatement) (any, error)
+	visitBlockStatement(bs *blockStatement) (any, error)
+	visitForInStatement(fs *forInStatement) (any, error)
 }
 
 type printStatement struct {
-	expr Expression
+	expr      Expression
+	keyword   Token
+	semicolon Token
 }
 
 type exprStatement struct {
-	expr Expression
+	expr      Expression
+	semicolon Token
 }
 
 func (ps *printStatement) accept(visitor statementVisitor) (any, error) {
@@ -24,3 +31,46 @@ func (ps *printStatement) accept(visitor statementVisitor) (any, error) {
 func (ps *exprStatement) accept(visitor statementVisitor) (any, error) {
 	return visitor.visitExprStatement(ps)
 }
+
+type breakStatement struct {
+	keyword   Token
+	semicolon Token
+}
+
+type continueStatement struct {
+	keyword   Token
+	semicolon Token
+}
+
+func (bs *breakStatement) accept(visitor statementVisitor) (any, error) {
+	return visitor.visitBreakStatement(bs)
+}
+
+func (cs *continueStatement) accept(visitor statementVisitor) (any, error) {
+	return visitor.visitContinueStatement(cs)
+}
+
+type blockStatement struct {
+	statements []Statement
+	leftBrace  Token
+	rightBrace Token
+}
+
+// forInStatement is `for (x in items) body` or `for (key, value in items) body`.
+type forInStatement struct {
+	keyword Token
+	// One or two names, the second one gets the values when there are two.
+	variables []Token
+	// The `in` keyword, its line is reported when the value can not be iterated over.
+	in       Token
+	iterable Expression
+	body     Statement
+}
+
+func (bs *blockStatement) accept(visitor statementVisitor) (any, error) {
+	return visitor.visitBlockStatement(bs)
+}
+
+func (fs *forInStatement) accept(visitor statementVisitor) (any, error) {
+	return visitor.visitForInStatement(fs)
+}
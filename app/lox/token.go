@@ -2,141 +2,234 @@ package lox
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 )
 
-type tokenType string
+// TokenType is the kind of the token. The values are the names printed by the `tokenize` command.
+type TokenType string
 
 const (
 	// Single-character tokens
-	LEFT_PAREN  tokenType = "LEFT_PAREN"
-	RIGHT_PAREN tokenType = "RIGHT_PAREN"
-	LEFT_BRACE  tokenType = "LEFT_BRACE"
-	RIGHT_BRACE tokenType = "RIGHT_BRACE"
-	COMMA       tokenType = "COMMA"
-	DOT         tokenType = "DOT"
-	MINUS       tokenType = "MINUS"
-	PLUS        tokenType = "PLUS"
-	SEMICOLON   tokenType = "SEMICOLON"
-	SLASH       tokenType = "SLASH"
-	STAR        tokenType = "STAR"
+	LEFT_PAREN    TokenType = "LEFT_PAREN"
+	RIGHT_PAREN   TokenType = "RIGHT_PAREN"
+	LEFT_BRACE    TokenType = "LEFT_BRACE"
+	RIGHT_BRACE   TokenType = "RIGHT_BRACE"
+	LEFT_BRACKET  TokenType = "LEFT_BRACKET"
+	RIGHT_BRACKET TokenType = "RIGHT_BRACKET"
+	COMMA         TokenType = "COMMA"
+	DOT           TokenType = "DOT"
+	MINUS         TokenType = "MINUS"
+	PLUS          TokenType = "PLUS"
+	SEMICOLON     TokenType = "SEMICOLON"
+	SLASH         TokenType = "SLASH"
+	STAR          TokenType = "STAR"
+	PERCENT       TokenType = "PERCENT"
+	AMPERSAND     TokenType = "AMPERSAND"
+	PIPE          TokenType = "PIPE"
+	CARET         TokenType = "CARET"
+	COLON         TokenType = "COLON"
 
 	// One or two character tokens
-	BANG          tokenType = "BANG"
-	BANG_EQUAL    tokenType = "BANG_EQUAL"
-	EQUAL         tokenType = "EQUAL"
-	EQUAL_EQUAL   tokenType = "EQUAL_EQUAL"
-	GREATER       tokenType = "GREATER"
-	GREATER_EQUAL tokenType = "GREATER_EQUAL"
-	LESS          tokenType = "LESS"
-	LESS_EQUAL    tokenType = "LESS_EQUAL"
+	BANG              TokenType = "BANG"
+	BANG_EQUAL        TokenType = "BANG_EQUAL"
+	EQUAL             TokenType = "EQUAL"
+	EQUAL_EQUAL       TokenType = "EQUAL_EQUAL"
+	GREATER           TokenType = "GREATER"
+	GREATER_EQUAL     TokenType = "GREATER_EQUAL"
+	GREATER_GREATER   TokenType = "GREATER_GREATER"
+	LESS              TokenType = "LESS"
+	LESS_EQUAL        TokenType = "LESS_EQUAL"
+	LESS_LESS         TokenType = "LESS_LESS"
+	MINUS_EQUAL       TokenType = "MINUS_EQUAL"
+	MINUS_MINUS       TokenType = "MINUS_MINUS"
+	PLUS_EQUAL        TokenType = "PLUS_EQUAL"
+	PLUS_PLUS         TokenType = "PLUS_PLUS"
+	QUESTION          TokenType = "QUESTION"
+	QUESTION_DOT      TokenType = "QUESTION_DOT"
+	QUESTION_QUESTION TokenType = "QUESTION_QUESTION"
+	SLASH_EQUAL       TokenType = "SLASH_EQUAL"
+	STAR_EQUAL        TokenType = "STAR_EQUAL"
+	STAR_STAR         TokenType = "STAR_STAR"
+	TILDE             TokenType = "TILDE"
+	// The integer division, `//` is already taken by the comments.
+	TILDE_SLASH TokenType = "TILDE_SLASH"
 
 	// Literals
-	IDENTIFIER tokenType = "IDENTIFIER"
-	STRING     tokenType = "STRING"
-	NUMBER     tokenType = "NUMBER"
+	IDENTIFIER TokenType = "IDENTIFIER"
+	STRING     TokenType = "STRING"
+	NUMBER     TokenType = "NUMBER"
+	// The part of a string literal that is followed by an interpolated expression.
+	// Example: "Hello ${
+	INTERPOLATION TokenType = "INTERPOLATION"
 
 	// Keywords
-	AND    tokenType = "AND"
-	CLASS  tokenType = "CLASS"
-	ELSE   tokenType = "ELSE"
-	FALSE  tokenType = "FALSE"
-	FUN    tokenType = "FUN"
-	FOR    tokenType = "FOR"
-	IF     tokenType = "IF"
-	NIL    tokenType = "NIL"
-	OR     tokenType = "OR"
-	PRINT  tokenType = "PRINT"
-	RETURN tokenType = "RETURN"
-	SUPER  tokenType = "SUPER"
-	THIS   tokenType = "THIS"
-	TRUE   tokenType = "TRUE"
-	VAR    tokenType = "VAR"
-	WHILE  tokenType = "WHILE"
-
-	EOF tokenType = "EOF"
+	AND      TokenType = "AND"
+	BREAK    TokenType = "BREAK"
+	CLASS    TokenType = "CLASS"
+	CONTINUE TokenType = "CONTINUE"
+	ELSE     TokenType = "ELSE"
+	FALSE    TokenType = "FALSE"
+	FUN      TokenType = "FUN"
+	FOR      TokenType = "FOR"
+	IF       TokenType = "IF"
+	IN       TokenType = "IN"
+	NIL      TokenType = "NIL"
+	OR       TokenType = "OR"
+	PRINT    TokenType = "PRINT"
+	RETURN   TokenType = "RETURN"
+	SUPER    TokenType = "SUPER"
+	THIS     TokenType = "THIS"
+	TRUE     TokenType = "TRUE"
+	VAR      TokenType = "VAR"
+	WHILE    TokenType = "WHILE"
+
+	EOF TokenType = "EOF"
 )
 
-var tokenLexemes = map[tokenType]string{
+// tokenLexemes maps the token types to their fixed lexemes.
+var tokenLexemes = map[TokenType]string{
 	// Single-character tokens
-	LEFT_PAREN:  "(",
-	RIGHT_PAREN: ")",
-	LEFT_BRACE:  "{",
-	RIGHT_BRACE: "}",
-	COMMA:       ",",
-	DOT:         ".",
-	MINUS:       "-",
-	PLUS:        "+",
-	SEMICOLON:   ";",
-	SLASH:       "/",
-	STAR:        "*",
+	LEFT_PAREN:    "(",
+	RIGHT_PAREN:   ")",
+	LEFT_BRACE:    "{",
+	RIGHT_BRACE:   "}",
+	LEFT_BRACKET:  "[",
+	RIGHT_BRACKET: "]",
+	COMMA:         ",",
+	DOT:           ".",
+	MINUS:         "-",
+	PLUS:          "+",
+	SEMICOLON:     ";",
+	SLASH:         "/",
+	STAR:          "*",
+	PERCENT:       "%",
+	AMPERSAND:     "&",
+	PIPE:          "|",
+	CARET:         "^",
+	COLON:         ":",
 
 	// One or two character tokens
-	BANG:          "!",
-	BANG_EQUAL:    "!=",
-	EQUAL:         "=",
-	EQUAL_EQUAL:   "==",
-	GREATER:       ">",
-	GREATER_EQUAL: ">=",
-	LESS:          "<",
-	LESS_EQUAL:    "<=",
+	BANG:              "!",
+	BANG_EQUAL:        "!=",
+	EQUAL:             "=",
+	EQUAL_EQUAL:       "==",
+	GREATER:           ">",
+	GREATER_EQUAL:     ">=",
+	GREATER_GREATER:   ">>",
+	LESS:              "<",
+	LESS_EQUAL:        "<=",
+	LESS_LESS:         "<<",
+	MINUS_EQUAL:       "-=",
+	MINUS_MINUS:       "--",
+	PLUS_EQUAL:        "+=",
+	PLUS_PLUS:         "++",
+	QUESTION:          "?",
+	QUESTION_DOT:      "?.",
+	QUESTION_QUESTION: "??",
+	SLASH_EQUAL:       "/=",
+	STAR_EQUAL:        "*=",
+	STAR_STAR:         "**",
+	TILDE:             "~",
+	TILDE_SLASH:       "~/",
 
 	// Keywords
-	AND:    "and",
-	CLASS:  "class",
-	ELSE:   "else",
-	FALSE:  "false",
-	FUN:    "fun",
-	FOR:    "for",
-	IF:     "if",
-	NIL:    "nil",
-	OR:     "or",
-	PRINT:  "print",
-	RETURN: "return",
-	SUPER:  "super",
-	THIS:   "this",
-	TRUE:   "true",
-	VAR:    "var",
-	WHILE:  "while",
+	AND:      "and",
+	BREAK:    "break",
+	CLASS:    "class",
+	CONTINUE: "continue",
+	ELSE:     "else",
+	FALSE:    "false",
+	FUN:      "fun",
+	FOR:      "for",
+	IF:       "if",
+	IN:       "in",
+	NIL:      "nil",
+	OR:       "or",
+	PRINT:    "print",
+	RETURN:   "return",
+	SUPER:    "super",
+	THIS:     "this",
+	TRUE:     "true",
+	VAR:      "var",
+	WHILE:    "while",
 
 	// Special tokens
 	EOF: "",
 }
 
-// Map of keywords where key is the keyword string and value is the TokenType
-var keywords = map[string]tokenType{
-	"and":    AND,
-	"class":  CLASS,
-	"else":   ELSE,
-	"false":  FALSE,
-	"fun":    FUN,
-	"for":    FOR,
-	"if":     IF,
-	"nil":    NIL,
-	"or":     OR,
-	"print":  PRINT,
-	"return": RETURN,
-	"super":  SUPER,
-	"this":   THIS,
-	"true":   TRUE,
-	"var":    VAR,
-	"while":  WHILE,
+// Map of keywords where key is the keyword string and value is the TokenType.
+var keywords = map[string]TokenType{
+	"and":      AND,
+	"break":    BREAK,
+	"class":    CLASS,
+	"continue": CONTINUE,
+	"else":     ELSE,
+	"false":    FALSE,
+	"fun":      FUN,
+	"for":      FOR,
+	"if":       IF,
+	"in":       IN,
+	"nil":      NIL,
+	"or":       OR,
+	"print":    PRINT,
+	"return":   RETURN,
+	"super":    SUPER,
+	"this":     THIS,
+	"true":     TRUE,
+	"var":      VAR,
+	"while":    WHILE,
 }
 
-type token struct {
-	Type    tokenType
+// Token is a single lexeme of the source code, together with the value it stands for and its position.
+type Token struct {
+	Type    TokenType
 	Lexeme  *string
 	Literal any
-	Line    int
+	// Where the token starts, columns count characters starting from 1.
+	Line   int
+	Column int
+
+	// Only populated when the Lox instance was created with the `WithTrivia` option.
+	LeadingTrivia  []Trivia
+	TrailingTrivia []Trivia
 }
 
-func newToken(tokenType tokenType, line int) token {
+// TriviaKind is the kind of the trivia.
+type TriviaKind string
+
+const (
+	WHITESPACE TriviaKind = "WHITESPACE"
+	NEWLINE    TriviaKind = "NEWLINE"
+	COMMENT    TriviaKind = "COMMENT"
+	// Characters that could not be tokenized, like an unexpected character or an unterminated string.
+	SKIPPED TriviaKind = "SKIPPED"
+)
+
+// Trivia is the part of the source code that does not affect the meaning of the program.
+type Trivia struct {
+	Kind TriviaKind
+	Text string
+}
+
+// appendTrivia merges consecutive whitespace and newlines into a single trivia.
+func appendTrivia(trivias []Trivia, kind TriviaKind, text string) []Trivia {
+	last := len(trivias) - 1
+	if last >= 0 && trivias[last].Kind == kind && (kind == WHITESPACE || kind == NEWLINE) {
+		trivias[last].Text += text
+		return trivias
+	}
+
+	return append(trivias, Trivia{Kind: kind, Text: text})
+}
+
+func newToken(tokenType TokenType, line int) Token {
 	lexme, found := tokenLexemes[tokenType]
 	if !found {
 		panic(fmt.Sprintf("could not find lexme for tokenType: %v", tokenType))
 	}
 
-	return token{
+	return Token{
 		Type:    tokenType,
 		Lexeme:  &lexme,
 		Literal: nil,
@@ -144,10 +237,9 @@ func newToken(tokenType tokenType, line int) token {
 	}
 }
 
-func newStringToken(value string, line int) token {
-	lexme := fmt.Sprintf("\"%v\"", value)
-
-	return token{
+// The lexeme is the string literal as it appears in the source code, including the quotes and escape sequences.
+func newStringToken(lexme string, value string, line int) Token {
+	return Token{
 		Type:    STRING,
 		Lexeme:  &lexme,
 		Literal: value,
@@ -155,8 +247,18 @@ func newStringToken(value string, line int) token {
 	}
 }
 
-func newIdentifierToken(value string, line int) token {
-	return token{
+// The lexeme spans from the opening quote, or the `}` of the previous interpolated expression, up to and including the `${`.
+func newInterpolationToken(lexme string, value string, line int) Token {
+	return Token{
+		Type:    INTERPOLATION,
+		Lexeme:  &lexme,
+		Literal: value,
+		Line:    line,
+	}
+}
+
+func newIdentifierToken(value string, line int) Token {
+	return Token{
 		Type:    IDENTIFIER,
 		Lexeme:  &value,
 		Literal: nil,
@@ -164,13 +266,11 @@ func newIdentifierToken(value string, line int) token {
 	}
 }
 
-func newNumberToken(value string, line int) token {
-	literal, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		panic(err)
-	}
-
-	return token{
+// The lexeme is the number literal as it appears in the source code, the value is what it stands for.
+// The value is an int64 for the integer literals and a float64 for the ones with a decimal point or an exponent.
+// The literals with the `n` suffix are *big.Int and the ones with the `d` suffix are decimal.
+func newNumberToken(value string, literal any, line int) Token {
+	return Token{
 		Type:    NUMBER,
 		Lexeme:  &value,
 		Literal: literal,
@@ -178,7 +278,7 @@ func newNumberToken(value string, line int) token {
 	}
 }
 
-func (t token) String() string {
+func (t Token) String() string {
 	lexme := "null"
 	if t.Lexeme != nil {
 		lexme = *t.Lexeme
@@ -194,23 +294,23 @@ func (t token) String() string {
 		return fmt.Sprintf("%v %v %v\n", t.Type, lexme, literal)
 	}
 
-	formatted, err := formatToDecimalString(lexme)
-	if err != nil {
-		panic(fmt.Errorf("could not parse number to literal: %w", err))
-	}
-
-	return fmt.Sprintf("%v %v %v\n", t.Type, lexme, formatted)
+	return fmt.Sprintf("%v %v %v\n", t.Type, lexme, formatToDecimalString(t.Literal))
 }
 
-func formatToDecimalString(value string) (string, error) {
-	num, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse number from string: %w", err)
+// formatToDecimalString formats the number with at least one decimal place, integers included.
+func formatToDecimalString(literal any) string {
+	if d, ok := literal.(decimal); ok && d.scale > 0 {
+		return d.String()
+	}
+
+	num, ok := literal.(float64)
+	if !ok {
+		return fmt.Sprintf("%v.0", literal)
 	}
 
-	if num == float64(int(num)) {
-		return fmt.Sprintf("%.1f", num), nil
+	if num == math.Trunc(num) && !math.IsInf(num, 0) {
+		return strconv.FormatFloat(num, 'f', 1, 64)
 	}
 
-	return fmt.Sprintf("%v", num), nil
+	return fmt.Sprintf("%v", num)
 }
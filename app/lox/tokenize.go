@@ -5,12 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"math/big"
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type UnexpectedTokenError struct {
 	Message string
 	Line    int
+	// The column where the token that could not be scanned starts.
+	Column int
 }
 
 func (e UnexpectedTokenError) Error() string {
@@ -18,234 +25,869 @@ func (e UnexpectedTokenError) Error() string {
 }
 
 type TokenizeResult struct {
-	Tokens []token
+	Tokens []Token
 	Errors []UnexpectedTokenError
 }
 
+// Source reconstructs the source code from the tokens.
+// The result matches the input byte-for-byte only if the tokens were produced with the trivia.
+func (tr TokenizeResult) Source() string {
+	source := ""
+	for _, t := range tr.Tokens {
+		for _, tv := range t.LeadingTrivia {
+			source += tv.Text
+		}
+
+		if t.Lexeme != nil {
+			source += *t.Lexeme
+		}
+
+		for _, tv := range t.TrailingTrivia {
+			source += tv.Text
+		}
+	}
+
+	return source
+}
+
 func (l *Lox) Tokenize(r io.Reader) (TokenizeResult, error) {
-	reader := bufio.NewReader(r)
-	line := 1
+	scanner := l.Scanner(r)
 
 	var tokenErrors []UnexpectedTokenError
-	var tokens []token
+	var tokens []Token
 
 	for {
-		b, err := reader.ReadByte()
+		token, err := scanner.Next()
 		if err != nil {
+			var tokenError UnexpectedTokenError
+			if errors.As(err, &tokenError) {
+				tokenErrors = append(tokenErrors, tokenError)
+				continue
+			}
+
+			return TokenizeResult{}, err
+		}
+
+		tokens = append(tokens, token)
+		if token.Type == EOF {
+			break
+		}
+	}
+
+	return TokenizeResult{
+		Tokens: tokens,
+		Errors: tokenErrors,
+	}, nil
+}
+
+// Scanner produces tokens one at a time, reading only as much of the input as it needs to.
+type Scanner struct {
+	reader *bufio.Reader
+	// Set once the EOF token has been returned.
+	done bool
+	line int
+	// The number of characters consumed on the current line.
+	column int
+
+	// Where the token that is currently being scanned starts.
+	tokenLine   int
+	tokenColumn int
+
+	// The number of unclosed braces in every string interpolation that is currently open.
+	interpolations []int
+
+	// When enabled, the skipped parts of the input are attached to the tokens as trivia.
+	trivia bool
+	// The trivia that will become the leading trivia of the next token.
+	pending []Trivia
+}
+
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{reader: bufio.NewReader(r), line: 1}
+}
+
+// Scanner creates a scanner that tokenizes the input the same way `Tokenize` does.
+func (l *Lox) Scanner(r io.Reader) *Scanner {
+	scanner := NewScanner(r)
+	scanner.trivia = l.trivia
+	return scanner
+}
+
+// Next returns the next token from the input.
+// Lexical errors are returned as UnexpectedTokenError, and the scanning can continue after them.
+// Any other error means the input could not be read.
+// The last token is always the EOF token, after which Next returns io.EOF.
+func (s *Scanner) Next() (Token, error) {
+	if s.done {
+		return Token{}, io.EOF
+	}
+
+	t, err := s.scan()
+	if err != nil {
+		var tokenError UnexpectedTokenError
+		if errors.As(err, &tokenError) {
+			tokenError.Column = s.tokenColumn
+			return Token{}, tokenError
+		}
+
+		return Token{}, err
+	}
+
+	t.Line, t.Column = s.tokenLine, s.tokenColumn
+	s.done = t.Type == EOF
+	if !s.trivia {
+		return t, nil
+	}
+
+	t.LeadingTrivia = s.pending
+	s.pending = nil
+
+	if t.Type == EOF {
+		return t, nil
+	}
+
+	trailing, err := s.scanTrailingTrivia()
+	if err != nil {
+		return Token{}, err
+	}
+	t.TrailingTrivia = trailing
+
+	return t, nil
+}
+
+// All returns an iterator over the tokens and the lexical errors, up to and including the EOF token.
+// The iteration stops early if the input could not be read.
+func (s *Scanner) All() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		for {
+			t, err := s.Next()
 			if errors.Is(err, io.EOF) {
-				tokens = append(tokens, newToken(EOF, line))
+				return
+			}
+
+			if !yield(t, err) {
+				return
+			}
+
+			if err != nil && !errors.As(err, &UnexpectedTokenError{}) {
+				return
+			}
+		}
+	}
+}
+
+// scanTrailingTrivia consumes the whitespace and the comment that follow a token on the same line.
+// The newline itself becomes the leading trivia of the next token.
+func (s *Scanner) scanTrailingTrivia() ([]Trivia, error) {
+	var trailing []Trivia
+
+	for {
+		next := peekNext(s.reader)
+		switch {
+		case next == " " || next == "\t" || next == "\r":
+			{
+				b, _ := s.readByte()
+				trailing = appendTrivia(trailing, WHITESPACE, string(b))
+			}
+		case next == tokenLexemes[SLASH] && peekString(s.reader, 2) == "//":
+			{
+				comment, err := s.scanLineComment()
+				if err != nil {
+					return nil, err
+				}
+				trailing = appendTrivia(trailing, COMMENT, comment)
+			}
+		default:
+			{
+				return trailing, nil
+			}
+		}
+	}
+}
+
+// scanLineComment consumes the `//` comment up to, but not including, the newline.
+func (s *Scanner) scanLineComment() (string, error) {
+	comment := ""
+	for {
+		next := peekNext(s.reader)
+		if next == "" || next == "\n" {
+			return comment, nil
+		}
+
+		r, err := s.readRune()
+		if err != nil {
+			return "", fmt.Errorf("failed to consume rest of the comment: %w", err)
+		}
+		comment += r
+	}
+}
+
+// scanBlockComment consumes the `/* ... */` comment, the opening `/` has already been consumed.
+// Block comments nest, so every `/*` inside the comment needs its own `*/`.
+func (s *Scanner) scanBlockComment() error {
+	startLine := s.line
+
+	star, _ := s.readRune()
+	comment := tokenLexemes[SLASH] + star
+	depth := 1
+
+	for depth > 0 {
+		r, err := s.readRune()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return fmt.Errorf("failed to consume the block comment: %w", err)
+			}
+
+			s.addTrivia(SKIPPED, comment)
+			return UnexpectedTokenError{Line: startLine, Message: "Unterminated block comment."}
+		}
+
+		comment += r
+		switch {
+		case r == "\n":
+			s.line += 1
+		case r == tokenLexemes[SLASH] && peekNext(s.reader) == tokenLexemes[STAR]:
+			r, _ := s.readRune()
+			comment += r
+			depth += 1
+		case r == tokenLexemes[STAR] && peekNext(s.reader) == tokenLexemes[SLASH]:
+			r, _ := s.readRune()
+			comment += r
+			depth -= 1
+		}
+	}
+
+	s.addTrivia(COMMENT, comment)
+	return nil
+}
+
+// scanString consumes a string literal up to the closing quote, or up to the next `${`.
+// The `start` is either the opening quote, or the `}` that closes an interpolated expression.
+func (s *Scanner) scanString(start string) (Token, error) {
+	reader := s.reader
+
+	// We have to operate on bytes here.
+	// Some characters might span multiple bytes.
+	// Take "ॐ" for example.
+	// Converting a single byte to a string with st := string(bt) would split this character into multiple pieces.
+	// Appending these multiple pieces to the "contents" would produce issues when formatting that character.
+	contents := make([]byte, 0)
+	lexeme := []byte(start)
+	var escapeErr error
+	for {
+		bt, err := s.readByte()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return Token{}, fmt.Errorf("failed to consume the string: %w", err)
+			}
+
+			s.addTrivia(SKIPPED, string(lexeme))
+			return Token{}, UnexpectedTokenError{Line: s.line, Message: "Unterminated string."}
+		}
+
+		lexeme = append(lexeme, bt)
+
+		if bt == '\n' {
+			s.line += 1
+			contents = append(contents, bt)
+			continue
+		}
+
+		if bt == '\\' {
+			raw, decoded, err := s.scanEscape()
+			lexeme = append(lexeme, raw...)
+			if err != nil {
+				var tokenError UnexpectedTokenError
+				if !errors.As(err, &tokenError) {
+					return Token{}, err
+				}
+
+				// Only the first invalid escape is reported, the rest of the string is still consumed.
+				if escapeErr == nil {
+					escapeErr = err
+				}
+				continue
+			}
+
+			contents = append(contents, decoded...)
+			continue
+		}
+
+		isInterpolation := bt == '$' && peekNext(reader) == tokenLexemes[LEFT_BRACE]
+		if bt == '"' || isInterpolation {
+			if escapeErr != nil {
+				s.addTrivia(SKIPPED, string(lexeme))
+				return Token{}, escapeErr
+			}
+
+			if !isInterpolation {
+				return newStringToken(string(lexeme), string(contents), s.line), nil
+			}
+
+			b, _ := s.readByte()
+			lexeme = append(lexeme, b)
+			s.interpolations = append(s.interpolations, 0)
+			return newInterpolationToken(string(lexeme), string(contents), s.line), nil
+		}
+
+		contents = append(contents, bt)
+	}
+}
+
+// scanNumber consumes a number literal, the first digit has already been consumed.
+// Supported forms are decimals (`12`, `12.5`, `2.5E3`, `1e-9`), hexadecimals (`0x1F`) and binaries (`0b1010`).
+// The digits can be separated with underscores (`1_000_000`).
+func (s *Scanner) scanNumber(first string) (Token, error) {
+	reader := s.reader
+	number := first
+
+	invalid := func() (Token, error) {
+		s.addTrivia(SKIPPED, number)
+		return Token{}, UnexpectedTokenError{Line: s.line, Message: fmt.Sprintf("Invalid number literal: %s.", number)}
+	}
+
+	integer := func(digits string, base int) (Token, error) {
+		if digits, ok := strings.CutSuffix(digits, "n"); ok {
+			value, ok := new(big.Int).SetString(digits, base)
+			if !ok {
+				return invalid()
+			}
+			return newNumberToken(number, value, s.line), nil
+		}
+
+		value, err := strconv.ParseInt(digits, base, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			s.addTrivia(SKIPPED, number)
+			return Token{}, UnexpectedTokenError{Line: s.line, Message: fmt.Sprintf("Integer literal is out of range: %s.", number)}
+		}
+		if err != nil {
+			return invalid()
+		}
+
+		return newNumberToken(number, value, s.line), nil
+	}
+
+	prefix := peekNext(reader)
+	if first == "0" && (prefix == "x" || prefix == "X" || prefix == "b" || prefix == "B") {
+		b, _ := s.readByte()
+		number += string(b)
+
+		// Everything up to the next non-alphanumeric character is considered a part of the literal,
+		// so that `0b102` is reported as invalid instead of being split into two tokens.
+		for isAlphaNumeric(peekNext(reader)) {
+			r, _ := s.readRune()
+			number += r
+		}
+
+		base := 16
+		if prefix == "b" || prefix == "B" {
+			base = 2
+		}
+
+		digits, suffix := strings.CutSuffix(number[2:], "n")
+		digits, ok := removeDigitSeparators(digits, base)
+		if !ok {
+			return invalid()
+		}
+		if suffix {
+			digits += "n"
+		}
+
+		return integer(digits, base)
+	}
+
+	scanDigits := func() {
+		for isDigit(peekNext(reader)) || peekNext(reader) == "_" {
+			b, _ := s.readByte()
+			number += string(b)
+		}
+	}
+
+	scanDigits()
+
+	if peekNext(reader) == "." {
+		b, _ := s.readByte()
+		number += string(b)
+
+		scanDigits()
+	}
+
+	// The exponent is only consumed if digits follow it, otherwise `e` starts an identifier.
+	// Every byte is peeked only when it is needed, so that the scanner does not wait for input it does not need.
+	if next := peekNext(reader); next == "e" || next == "E" {
+		digitAt := 1
+		if sign := peekString(reader, 2)[1:]; sign == "+" || sign == "-" {
+			digitAt = 2
+		}
+
+		exponent := peekString(reader, digitAt+1)
+		if len(exponent) > digitAt && isDigit(string(exponent[digitAt])) {
+			for range digitAt {
+				b, _ := s.readByte()
+				number += string(b)
+			}
+
+			scanDigits()
+		}
+	}
+
+	suffix := s.scanNumberSuffix()
+	number += suffix
+
+	digits, ok := removeDigitSeparators(strings.TrimSuffix(number, suffix), 10)
+	if !ok {
+		return invalid()
+	}
+
+	if suffix == "d" {
+		value, ok := parseDecimal(digits)
+		if !ok {
+			return invalid()
+		}
+		return newNumberToken(number, value, s.line), nil
+	}
+
+	if !strings.ContainsAny(digits, ".eE") {
+		return integer(digits+suffix, 10)
+	}
+
+	if suffix != "" {
+		return invalid()
+	}
+
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return invalid()
+	}
+
+	return newNumberToken(number, value, s.line), nil
+}
+
+// scanNumberSuffix consumes the `n` of the big integers or the `d` of the decimals, like in `123n` and `1.10d`.
+// The suffix has to end the literal, `12nd` is the number 12 followed by the identifier `nd`.
+func (s *Scanner) scanNumberSuffix() string {
+	next := peekNext(s.reader)
+	if next != "n" && next != "d" {
+		return ""
+	}
+
+	after := peekString(s.reader, 2)[1:]
+	if after != "" && after[0] >= utf8.RuneSelf {
+		// Only the first byte of the character has been peeked, so the whole character is peeked to check it.
+		after = peekString(s.reader, 1+utf8.UTFMax)[1:]
+		r, size := utf8.DecodeRuneInString(after)
+		after = after[:size]
+		if r == utf8.RuneError {
+			after = ""
+		}
+	}
+	if isAlphaNumeric(after) {
+		return ""
+	}
+
+	s.readByte()
+	return next
+}
+
+// removeDigitSeparators removes the underscores from the digits.
+// Every underscore has to be placed between two digits, otherwise the digits are not valid.
+func removeDigitSeparators(digits string, base int) (string, bool) {
+	if digits == "" {
+		return "", false
+	}
+
+	result := ""
+	for i, r := range digits {
+		if r != '_' {
+			result += string(r)
+			continue
+		}
+
+		if i == 0 || i == len(digits)-1 || !isDigitOfBase(digits[i-1], base) || !isDigitOfBase(digits[i+1], base) {
+			return "", false
+		}
+	}
+
+	return result, true
+}
+
+func isDigitOfBase(b byte, base int) bool {
+	_, err := strconv.ParseUint(string(b), base, 8)
+	return err == nil
+}
+
+// scanEscape consumes the escape sequence that follows the `\\` in a string literal.
+// It returns the consumed source and the bytes that the escape sequence stands for.
+func (s *Scanner) scanEscape() ([]byte, []byte, error) {
+	line := s.line
+
+	b, err := s.readByte()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			// The string is unterminated, which is reported by the caller.
+			return nil, nil, nil
+		}
+
+		return nil, nil, fmt.Errorf("failed to consume the escape sequence: %w", err)
+	}
+
+	switch b {
+	case '"', '\\', '$':
+		return []byte{b}, []byte{b}, nil
+	case 'n':
+		return []byte{b}, []byte("\n"), nil
+	case 't':
+		return []byte{b}, []byte("\t"), nil
+	case 'r':
+		return []byte{b}, []byte("\r"), nil
+	case '0':
+		return []byte{b}, []byte{0}, nil
+	case 'u':
+		raw := []byte{b}
+		if peekNext(s.reader) != tokenLexemes[LEFT_BRACE] {
+			return raw, nil, UnexpectedTokenError{Line: line, Message: "Invalid unicode escape sequence: expected '{' after \\u."}
+		}
+
+		b, _ := s.readByte()
+		raw = append(raw, b)
+
+		digits := ""
+		for {
+			next := peekNext(s.reader)
+			if next == "" || next == "\"" || next == "\n" {
+				return raw, nil, UnexpectedTokenError{Line: line, Message: "Invalid unicode escape sequence: missing '}'."}
+			}
+
+			b, _ := s.readByte()
+			raw = append(raw, b)
+			if b == '}' {
 				break
 			}
+			digits += string(b)
+		}
+
+		code, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+			return raw, nil, UnexpectedTokenError{Line: line, Message: fmt.Sprintf("Invalid unicode escape sequence: \\u%s.", raw[1:])}
+		}
+
+		return raw, utf8.AppendRune(nil, rune(code)), nil
+	default:
+		if b == '\n' {
+			s.line += 1
+		}
+
+		return []byte{b}, nil, UnexpectedTokenError{Line: line, Message: fmt.Sprintf("Invalid escape sequence: \\%s.", string(b))}
+	}
+}
+
+// readByte reads a single byte and keeps track of the column.
+// Columns count characters, so the continuation bytes of multi-byte characters do not move the column.
+func (s *Scanner) readByte() (byte, error) {
+	b, err := s.reader.ReadByte()
+	if err != nil {
+		return b, err
+	}
+
+	if b == '\n' {
+		s.column = 0
+	} else if utf8.RuneStart(b) {
+		s.column += 1
+	}
+
+	return b, nil
+}
+
+// readRune reads a single character and keeps track of the column.
+// The bytes that are not valid UTF-8 are returned one by one.
+func (s *Scanner) readRune() (string, error) {
+	r, size, err := s.reader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+
+	if r == utf8.RuneError && size == 1 {
+		s.reader.UnreadRune()
+		b, _ := s.reader.ReadByte()
+		s.column += 1
+		return string([]byte{b}), nil
+	}
+
+	if r == '\n' {
+		s.column = 0
+	} else {
+		s.column += 1
+	}
+
+	return string(r), nil
+}
+
+func (s *Scanner) addTrivia(kind TriviaKind, text string) {
+	if s.trivia {
+		s.pending = appendTrivia(s.pending, kind, text)
+	}
+}
+
+func (s *Scanner) scan() (Token, error) {
+	reader := s.reader
+
+	for {
+		s.tokenLine, s.tokenColumn = s.line, s.column+1
+
+		sb, err := s.readRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				if len(s.interpolations) > 0 {
+					s.interpolations = nil
+					return Token{}, UnexpectedTokenError{Line: s.line, Message: "Unterminated string."}
+				}
+
+				return newToken(EOF, s.line), nil
+			}
 
-			return TokenizeResult{}, fmt.Errorf("failed to read token: %w", err)
+			return Token{}, fmt.Errorf("failed to read token: %w", err)
 		}
 
-		sb := string(b)
 		switch sb {
 		case tokenLexemes[LEFT_BRACE]:
 			{
-				tokens = append(tokens, newToken(LEFT_BRACE, line))
+				if len(s.interpolations) > 0 {
+					s.interpolations[len(s.interpolations)-1] += 1
+				}
+				return newToken(LEFT_BRACE, s.line), nil
 			}
 		case tokenLexemes[RIGHT_BRACE]:
 			{
-				tokens = append(tokens, newToken(RIGHT_BRACE, line))
+				last := len(s.interpolations) - 1
+				if last >= 0 && s.interpolations[last] == 0 {
+					// The brace closes the interpolated expression, so the string continues after it.
+					s.interpolations = s.interpolations[:last]
+					return s.scanString(sb)
+				}
+
+				if last >= 0 {
+					s.interpolations[last] -= 1
+				}
+				return newToken(RIGHT_BRACE, s.line), nil
 			}
 		case tokenLexemes[LEFT_PAREN]:
 			{
-				tokens = append(tokens, newToken(LEFT_PAREN, line))
+				return newToken(LEFT_PAREN, s.line), nil
+			}
+		case tokenLexemes[LEFT_BRACKET]:
+			{
+				return newToken(LEFT_BRACKET, s.line), nil
+			}
+		case tokenLexemes[RIGHT_BRACKET]:
+			{
+				return newToken(RIGHT_BRACKET, s.line), nil
 			}
 		case tokenLexemes[RIGHT_PAREN]:
 			{
-				tokens = append(tokens, newToken(RIGHT_PAREN, line))
+				return newToken(RIGHT_PAREN, s.line), nil
 			}
 		case tokenLexemes[COMMA]:
 			{
-				tokens = append(tokens, newToken(COMMA, line))
+				return newToken(COMMA, s.line), nil
 			}
 		case tokenLexemes[DOT]:
 			{
-				tokens = append(tokens, newToken(DOT, line))
+				return newToken(DOT, s.line), nil
 			}
 		case tokenLexemes[MINUS]:
 			{
-				tokens = append(tokens, newToken(MINUS, line))
+				return s.scanOperator(MINUS, MINUS_MINUS, MINUS_EQUAL), nil
 			}
 		case tokenLexemes[PLUS]:
 			{
-				tokens = append(tokens, newToken(PLUS, line))
+				return s.scanOperator(PLUS, PLUS_PLUS, PLUS_EQUAL), nil
 			}
 		case tokenLexemes[SEMICOLON]:
 			{
-				tokens = append(tokens, newToken(SEMICOLON, line))
+				return newToken(SEMICOLON, s.line), nil
 			}
 		case tokenLexemes[STAR]:
 			{
-				tokens = append(tokens, newToken(STAR, line))
+				if peekNext(reader) == tokenLexemes[STAR] {
+					s.readByte()
+					return newToken(STAR_STAR, s.line), nil
+				}
+				return s.scanOperator(STAR, STAR_EQUAL), nil
+			}
+		case tokenLexemes[PERCENT]:
+			{
+				return newToken(PERCENT, s.line), nil
+			}
+		case tokenLexemes[AMPERSAND]:
+			{
+				return newToken(AMPERSAND, s.line), nil
+			}
+		case tokenLexemes[PIPE]:
+			{
+				return newToken(PIPE, s.line), nil
+			}
+		case tokenLexemes[CARET]:
+			{
+				return newToken(CARET, s.line), nil
+			}
+		case tokenLexemes[COLON]:
+			{
+				return newToken(COLON, s.line), nil
+			}
+		case tokenLexemes[QUESTION]:
+			{
+				return s.scanOperator(QUESTION, QUESTION_QUESTION, QUESTION_DOT), nil
+			}
+		case tokenLexemes[TILDE]:
+			{
+				// `~//` and `~/*` are the `~` followed by a comment, not the integer division.
+				next := peekString(reader, 2)
+				if strings.HasPrefix(next, tokenLexemes[SLASH]) && next != "//" && next != "/*" {
+					s.readByte()
+					return newToken(TILDE_SLASH, s.line), nil
+				}
+				return newToken(TILDE, s.line), nil
 			}
 		case tokenLexemes[BANG]:
 			{
-				matches, err := matchNextToken(reader, newToken(EQUAL, line))
+				matches, err := matchNextToken(reader, newToken(EQUAL, s.line))
 				if err != nil {
-					return TokenizeResult{}, fmt.Errorf("failed to match next token: %w", err)
+					return Token{}, fmt.Errorf("failed to match next token: %w", err)
 				}
 				if matches {
-					tokens = append(tokens, newToken(BANG_EQUAL, line))
-					reader.ReadByte()
+					s.readByte()
+					return newToken(BANG_EQUAL, s.line), nil
 				} else {
-					tokens = append(tokens, newToken(BANG, line))
+					return newToken(BANG, s.line), nil
 				}
 			}
 		case tokenLexemes[EQUAL]:
 			{
-				matches, err := matchNextToken(reader, newToken(EQUAL, line))
+				matches, err := matchNextToken(reader, newToken(EQUAL, s.line))
 				if err != nil {
-					return TokenizeResult{}, fmt.Errorf("failed to match next token: %w", err)
+					return Token{}, fmt.Errorf("failed to match next token: %w", err)
 				}
 				if matches {
-					tokens = append(tokens, newToken(EQUAL_EQUAL, line))
-					reader.ReadByte()
+					s.readByte()
+					return newToken(EQUAL_EQUAL, s.line), nil
 				} else {
-					tokens = append(tokens, newToken(EQUAL, line))
+					return newToken(EQUAL, s.line), nil
 				}
 			}
 		case tokenLexemes[LESS]:
 			{
-				matches, err := matchNextToken(reader, newToken(EQUAL, line))
+				matches, err := matchNextToken(reader, newToken(EQUAL, s.line))
 				if err != nil {
-					return TokenizeResult{}, fmt.Errorf("failed to match next token: %w", err)
+					return Token{}, fmt.Errorf("failed to match next token: %w", err)
 				}
 				if matches {
-					tokens = append(tokens, newToken(LESS_EQUAL, line))
-					reader.ReadByte()
+					s.readByte()
+					return newToken(LESS_EQUAL, s.line), nil
+				} else if peekNext(reader) == tokenLexemes[LESS] {
+					s.readByte()
+					return newToken(LESS_LESS, s.line), nil
 				} else {
-					tokens = append(tokens, newToken(LESS, line))
+					return newToken(LESS, s.line), nil
 				}
 			}
 		case tokenLexemes[GREATER]:
 			{
-				matches, err := matchNextToken(reader, newToken(EQUAL, line))
+				matches, err := matchNextToken(reader, newToken(EQUAL, s.line))
 				if err != nil {
-					return TokenizeResult{}, fmt.Errorf("failed to match next token: %w", err)
+					return Token{}, fmt.Errorf("failed to match next token: %w", err)
 				}
 				if matches {
-					tokens = append(tokens, newToken(GREATER_EQUAL, line))
-					reader.ReadByte()
+					s.readByte()
+					return newToken(GREATER_EQUAL, s.line), nil
+				} else if peekNext(reader) == tokenLexemes[GREATER] {
+					s.readByte()
+					return newToken(GREATER_GREATER, s.line), nil
 				} else {
-					tokens = append(tokens, newToken(GREATER, line))
+					return newToken(GREATER, s.line), nil
 				}
 			}
 		case tokenLexemes[SLASH]:
 			{
-				matches, err := matchNextToken(reader, newToken(SLASH, line))
+				matches, err := matchNextToken(reader, newToken(SLASH, s.line))
 				if err != nil {
-					return TokenizeResult{}, fmt.Errorf("failed to match next token: %w", err)
+					return Token{}, fmt.Errorf("failed to match next token: %w", err)
 				}
 				if matches {
-					_, err = reader.ReadString('\n')
+					comment, err := s.scanLineComment()
 					if err != nil {
-						if !errors.Is(err, io.EOF) {
-							return TokenizeResult{}, fmt.Errorf("failed to consume rest of the comment: %w", err)
-						}
+						return Token{}, err
+					}
+					s.addTrivia(COMMENT, sb+comment)
+				} else if peekNext(reader) == tokenLexemes[STAR] {
+					err := s.scanBlockComment()
+					if err != nil {
+						return Token{}, err
 					}
-					line += 1
 				} else {
-					tokens = append(tokens, newToken(SLASH, line))
+					return s.scanOperator(SLASH, SLASH_EQUAL), nil
 				}
 			}
 		case "\"":
 			{
-				// We have to operate on bytes here.
-				// Some characters might span multiple bytes.
-				// Take "ॐ" for example.
-				// Converting a single byte to a string with st := string(bt) would split this character into multiple pieces.
-				// Appending these multiple pieces to the "contents" would produce issues when formatting that character.
-				contents := make([]byte, 0)
-				for {
-					bt, err := reader.ReadByte()
-					if err != nil {
-						if !errors.Is(err, io.EOF) {
-							return TokenizeResult{}, fmt.Errorf("failed to consume the string: %w", err)
-						}
-
-						tokenErrors = append(tokenErrors, UnexpectedTokenError{Line: line, Message: "Unterminated string."})
-						break
-					}
-
-					if bt == '\n' {
-						line += 1
-						contents = append(contents, bt)
-						continue
-					}
-
-					if bt == '"' {
-						tokens = append(tokens, newStringToken(string(contents), line))
-						break
-					}
-
-					contents = append(contents, bt)
-				}
+				return s.scanString(sb)
 			}
-		case " ":
-		case "\r":
-		case "\t":
+		case " ", "\r", "\t":
 			{
-				continue
+				s.addTrivia(WHITESPACE, sb)
 			}
 		case "\n":
 			{
-				line += 1
+				s.line += 1
+				s.addTrivia(NEWLINE, sb)
 			}
 		default:
 			{
 				if isDigit(sb) {
-					number := sb
-
-					for isDigit(peekNext(reader)) {
-						b, _ := reader.ReadByte()
-						number += string(b)
-					}
-
-					if peekNext(reader) == "." {
-						b, _ := reader.ReadByte()
-						number += string(b)
-
-						for isDigit(peekNext(reader)) {
-							b, _ := reader.ReadByte()
-							number += string(b)
-						}
-					}
-
-					tokens = append(tokens, newNumberToken(number, line))
+					return s.scanNumber(sb)
 				} else if isAlphaNumeric(sb) {
 					content := sb
 
-					for isAlphaNumeric(peekNext(reader)) {
-						b, _ := reader.ReadByte()
-						content += string(b)
+					for isIdentifierPart(peekNext(reader)) {
+						r, _ := s.readRune()
+						content += r
 					}
 
 					keyword, found := keywords[content]
 					if found {
-						tokens = append(tokens, newToken(keyword, line))
+						return newToken(keyword, s.line), nil
 					} else {
-						tokens = append(tokens, newIdentifierToken(content, line))
+						return newIdentifierToken(content, s.line), nil
 					}
 
 				} else {
-					tokenErrors = append(tokenErrors, UnexpectedTokenError{Line: line, Message: fmt.Sprintf("Unexpected character: %v", sb)})
+					s.addTrivia(SKIPPED, sb)
+
+					character := sb
+					if !utf8.ValidString(sb) {
+						character = strings.Trim(strconv.Quote(sb), "\"")
+					}
+					return Token{}, UnexpectedTokenError{Line: s.line, Message: fmt.Sprintf("Unexpected character: %v", character)}
 				}
 			}
 		}
 	}
+}
 
-	return TokenizeResult{
-		Tokens: tokens,
-		Errors: tokenErrors,
-	}, nil
+// scanOperator returns the longest of the operators that starts with the character that has just been read.
+// The longer operators are two characters long, like `++` and `+=` for `+`.
+func (s *Scanner) scanOperator(single TokenType, longer ...TokenType) Token {
+	next := peekNext(s.reader)
+	for _, tokenType := range longer {
+		if tokenLexemes[tokenType][1:] == next {
+			s.readByte()
+			return newToken(tokenType, s.line)
+		}
+	}
 
+	return newToken(single, s.line)
 }
 
-func matchNextToken(r *bufio.Reader, matchToken token) (bool, error) {
+func matchNextToken(r *bufio.Reader, matchToken Token) (bool, error) {
 	nextB, err := r.Peek(1)
 	if err != nil {
 		if errors.Is(err, io.EOF) {
@@ -260,20 +902,15 @@ func matchNextToken(r *bufio.Reader, matchToken token) (bool, error) {
 }
 
 func isDigit(s string) bool {
-	if len(s) != 1 {
-		return false
-	}
-
-	r := rune(s[0])
-	return unicode.IsDigit(r)
+	return len(s) == 1 && s[0] >= '0' && s[0] <= '9'
 }
 
 func isAlpha(s string) bool {
-	if len(s) != 1 {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || r == utf8.RuneError {
 		return false
 	}
 
-	r := rune(s[0])
 	return unicode.IsLetter(r) || r == '_'
 }
 
@@ -281,15 +918,51 @@ func isAlphaNumeric(s string) bool {
 	return isDigit(s) || isAlpha(s)
 }
 
+// isIdentifierPart checks whether the character can continue an identifier.
+// Besides the letters and the digits, it accepts the combining marks and the digits of the other scripts,
+// so that `नमस्ते`, a decomposed `é` and `x٣` are single identifiers.
+func isIdentifierPart(s string) bool {
+	if isAlphaNumeric(s) {
+		return true
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || r == utf8.RuneError {
+		return false
+	}
+
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd)
+}
+
+// peekString returns up to n next bytes without consuming them.
+func peekString(r *bufio.Reader, n int) string {
+	next, _ := r.Peek(n)
+	return string(next)
+}
+
+// peekNext returns the next character without consuming it.
+// It only peeks as many bytes as the character is made of.
 func peekNext(r *bufio.Reader) string {
-	next, err := r.Peek(1)
+	first, err := r.Peek(1)
 	if err != nil {
-		if errors.Is(err, io.EOF) {
-			return ""
-		}
-
 		return ""
 	}
 
-	return string(next)
+	if first[0] < utf8.RuneSelf {
+		return string(first)
+	}
+
+	size := 1
+	switch {
+	case first[0]&0xE0 == 0xC0:
+		size = 2
+	case first[0]&0xF0 == 0xE0:
+		size = 3
+	case first[0]&0xF8 == 0xF0:
+		size = 4
+	}
+
+	next, _ := r.Peek(size)
+	_, size = utf8.DecodeRune(next)
+	return string(next[:size])
 }
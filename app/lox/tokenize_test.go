@@ -1,6 +1,10 @@
 package lox_test
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -19,6 +23,56 @@ func TestTokenize(t *testing.T) {
 			expectedOut: "LEFT_PAREN ( null\nLEFT_BRACE { null\nSTAR * null\nDOT . null\nCOMMA , null\nPLUS + null\nSTAR * null\nRIGHT_BRACE } null\nRIGHT_PAREN ) null\nEOF  null\n",
 			expectedErr: "",
 		},
+		{
+			input:       "% ** & | ^ ~ ~/ << >>",
+			expectedOut: "PERCENT % null\nSTAR_STAR ** null\nAMPERSAND & null\nPIPE | null\nCARET ^ null\nTILDE ~ null\nTILDE_SLASH ~/ null\nLESS_LESS << null\nGREATER_GREATER >> null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "~// comment\n~/* comment */",
+			expectedOut: "TILDE ~ null\nTILDE ~ null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "42 0x2A 4.2e1 9223372036854775808",
+			expectedOut: "NUMBER 42 42.0\nNUMBER 0x2A 42.0\nNUMBER 4.2e1 42.0\nEOF  null\n",
+			expectedErr: "[line 1] Error: Integer literal is out of range: 9223372036854775808.\n",
+		},
+		{
+			input:       "123n 0xFFn 1.10d 5d 12nd 1.5n",
+			expectedOut: "NUMBER 123n 123.0\nNUMBER 0xFFn 255.0\nNUMBER 1.10d 1.10\nNUMBER 5d 5.0\nNUMBER 12 12.0\nIDENTIFIER nd null\nEOF  null\n",
+			expectedErr: "[line 1] Error: Invalid number literal: 1.5n.\n",
+		},
+		{
+			input:       "1e3d 1e1000000000d 1e-1000000000d",
+			expectedOut: "NUMBER 1e3d 1000.0\nEOF  null\n",
+			expectedErr: "[line 1] Error: Invalid number literal: 1e1000000000d.\n[line 1] Error: Invalid number literal: 1e-1000000000d.\n",
+		},
+		{
+			input:       "a += b -= c *= d /= e++ --f",
+			expectedOut: "IDENTIFIER a null\nPLUS_EQUAL += null\nIDENTIFIER b null\nMINUS_EQUAL -= null\nIDENTIFIER c null\nSTAR_EQUAL *= null\nIDENTIFIER d null\nSLASH_EQUAL /= null\nIDENTIFIER e null\nPLUS_PLUS ++ null\nMINUS_MINUS -- null\nIDENTIFIER f null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "a ? b : c ?? d",
+			expectedOut: "IDENTIFIER a null\nQUESTION ? null\nIDENTIFIER b null\nCOLON : null\nIDENTIFIER c null\nQUESTION_QUESTION ?? null\nIDENTIFIER d null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "a?.b?.[0]",
+			expectedOut: "IDENTIFIER a null\nQUESTION_DOT ?. null\nIDENTIFIER b null\nQUESTION_DOT ?. null\nLEFT_BRACKET [ null\nNUMBER 0 0.0\nRIGHT_BRACKET ] null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "break continue",
+			expectedOut: "BREAK break null\nCONTINUE continue null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "for in",
+			expectedOut: "FOR for null\nIN in null\nEOF  null\n",
+			expectedErr: "",
+		},
 		{
 			input:       "4043.6490",
 			expectedOut: "NUMBER 4043.6490 4043.649\nEOF  null\n",
@@ -51,7 +105,7 @@ func TestTokenize(t *testing.T) {
 		},
 		{
 			input:       "() #    {}\n@\n$\n+++\n// Let's Go!\n+++\n#",
-			expectedOut: "LEFT_PAREN ( null\nRIGHT_PAREN ) null\nLEFT_BRACE { null\nRIGHT_BRACE } null\nPLUS + null\nPLUS + null\nPLUS + null\nPLUS + null\nPLUS + null\nPLUS + null\nEOF  null\n",
+			expectedOut: "LEFT_PAREN ( null\nRIGHT_PAREN ) null\nLEFT_BRACE { null\nRIGHT_BRACE } null\nPLUS_PLUS ++ null\nPLUS + null\nPLUS_PLUS ++ null\nPLUS + null\nEOF  null\n",
 			expectedErr: "[line 1] Error: Unexpected character: #\n[line 2] Error: Unexpected character: @\n[line 3] Error: Unexpected character: $\n[line 7] Error: Unexpected character: #\n",
 		},
 		{
@@ -74,6 +128,96 @@ func TestTokenize(t *testing.T) {
 			expectedOut: "COMMA , null\nDOT . null\nLEFT_PAREN ( null\nEOF  null\n",
 			expectedErr: "[line 1] Error: Unexpected character: $\n[line 1] Error: Unexpected character: #\n",
 		},
+		{
+			input:       "(/* comment */)/**/\n/* multi\nline\n*/\n+",
+			expectedOut: "LEFT_PAREN ( null\nRIGHT_PAREN ) null\nPLUS + null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "/* outer /* inner */ still a comment */ 1 /*/ not nested // */ 2",
+			expectedOut: "NUMBER 1 1.0\nNUMBER 2 2.0\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "1\n/* open\n/* nested */\n#",
+			expectedOut: "NUMBER 1 1.0\nEOF  null\n",
+			expectedErr: "[line 2] Error: Unterminated block comment.\n",
+		},
+		{
+			input:       "/* a\nb */ #",
+			expectedOut: "EOF  null\n",
+			expectedErr: "[line 2] Error: Unexpected character: #\n",
+		},
+		{
+			input:       "\"say \\\"hi\\\"\\t\\\\\" \"a\\nb\\r\\0\"",
+			expectedOut: "STRING \"say \\\"hi\\\"\\t\\\\\" say \"hi\"\t\\\nSTRING \"a\\nb\\r\\0\" a\nb\r\x00\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "\"\\u{41}\\u{1F600}\\u{0950}\"",
+			expectedOut: "STRING \"\\u{41}\\u{1F600}\\u{0950}\" A😀ॐ\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "\"ok\"\n\"bad \\q escape\" \"after\"",
+			expectedOut: "STRING \"ok\" ok\nSTRING \"after\" after\nEOF  null\n",
+			expectedErr: "[line 2] Error: Invalid escape sequence: \\q.\n",
+		},
+		{
+			input:       "\"multi\nline \\u{D800}\" \"\\u{110000}\" \"\\u41\" \"\\u{41\"",
+			expectedOut: "EOF  null\n",
+			expectedErr: "[line 2] Error: Invalid unicode escape sequence: \\u{D800}.\n[line 2] Error: Invalid unicode escape sequence: \\u{110000}.\n[line 2] Error: Invalid unicode escape sequence: expected '{' after \\u.\n[line 2] Error: Invalid unicode escape sequence: missing '}'.\n",
+		},
+		{
+			input:       "\"unterminated \\\"",
+			expectedOut: "EOF  null\n",
+			expectedErr: "[line 1] Error: Unterminated string.\n",
+		},
+		{
+			input:       "\"Hello ${name}, you have ${n + 1} items\"",
+			expectedOut: "INTERPOLATION \"Hello ${ Hello \nIDENTIFIER name null\nINTERPOLATION }, you have ${ , you have \nIDENTIFIER n null\nPLUS + null\nNUMBER 1 1.0\nSTRING } items\"  items\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "\"${ \"inner ${ {} }\" }\" \"\\${not} $ {}\"",
+			expectedOut: "INTERPOLATION \"${ \nINTERPOLATION \"inner ${ inner \nLEFT_BRACE { null\nRIGHT_BRACE } null\nSTRING }\" \nSTRING }\" \nSTRING \"\\${not} $ {}\" ${not} $ {}\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "\"open ${1",
+			expectedOut: "INTERPOLATION \"open ${ open \nNUMBER 1 1.0\nEOF  null\n",
+			expectedErr: "[line 1] Error: Unterminated string.\n",
+		},
+		{
+			input:       "0x1F 0XfF 0b1010 0B1 1e-9 2.5E3 1e+2 1_000_000 0xff_ff 0b1_0 3.141_592 0",
+			expectedOut: "NUMBER 0x1F 31.0\nNUMBER 0XfF 255.0\nNUMBER 0b1010 10.0\nNUMBER 0B1 1.0\nNUMBER 1e-9 1e-09\nNUMBER 2.5E3 2500.0\nNUMBER 1e+2 100.0\nNUMBER 1_000_000 1000000.0\nNUMBER 0xff_ff 65535.0\nNUMBER 0b1_0 2.0\nNUMBER 3.141_592 3.141592\nNUMBER 0 0.0\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "0x 0b102 0xG 1__0\n1_ 1_.5 1_e5 0x1_",
+			expectedOut: "EOF  null\n",
+			expectedErr: "[line 1] Error: Invalid number literal: 0x.\n[line 1] Error: Invalid number literal: 0b102.\n[line 1] Error: Invalid number literal: 0xG.\n[line 1] Error: Invalid number literal: 1__0.\n[line 2] Error: Invalid number literal: 1_.\n[line 2] Error: Invalid number literal: 1_.5.\n[line 2] Error: Invalid number literal: 1_e5.\n[line 2] Error: Invalid number literal: 0x1_.\n",
+		},
+		{
+			input:       "1e 2else 3e+ 0x1FFFFFFFFFFFFFFFF",
+			expectedOut: "NUMBER 1 1.0\nIDENTIFIER e null\nNUMBER 2 2.0\nELSE else null\nNUMBER 3 3.0\nIDENTIFIER e null\nPLUS + null\nEOF  null\n",
+			expectedErr: "[line 1] Error: Integer literal is out of range: 0x1FFFFFFFFFFFFFFFF.\n",
+		},
+		{
+			input:       "zażółć gęślą_jaźń ñ1 日本",
+			expectedOut: "IDENTIFIER zażółć null\nIDENTIFIER gęślą_jaźń null\nIDENTIFIER ñ1 null\nIDENTIFIER 日本 null\nEOF  null\n",
+			expectedErr: "",
+		},
+		{
+			input:       "नमस्ते e\u0301 x٣ ́x",
+			expectedOut: "IDENTIFIER नमस्ते null\nIDENTIFIER e\u0301 null\nIDENTIFIER x٣ null\nIDENTIFIER x null\nEOF  null\n",
+			expectedErr: "[line 1] Error: Unexpected character: \u0301\n",
+		},
+		{
+			input:       "→ + 😀\n\xff",
+			expectedOut: "PLUS + null\nEOF  null\n",
+			expectedErr: "[line 1] Error: Unexpected character: →\n[line 1] Error: Unexpected character: 😀\n[line 2] Error: Unexpected character: \\xff\n",
+		},
 		{
 			input:       "(()",
 			expectedOut: "LEFT_PAREN ( null\nLEFT_PAREN ( null\nRIGHT_PAREN ) null\nEOF  null\n",
@@ -123,3 +267,160 @@ func TestTokenize(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenizeTrivia(t *testing.T) {
+	inputs := []string{
+		"",
+		"print 1 + 2;",
+		"  print   1;   // trailing comment\n\n// leading comment\n\tprint 2;\r\n",
+		"() #    {}\n@\n$\n+++\n// Let's Go!\n+++\n#",
+		"\"bar\n\"foo\n\"\n\"bar",
+		"42\n42.42\n.42\n.42.42\n42.\n123.123\n67.0000",
+		"// only a comment",
+		"print /* inline */ 1; /* trailing\n block */ print 2; /* unterminated /* */",
+		"print \"ॐ\";\n",
+		"zażółć → \xff\xfe 😀 ; // ż /* ó */\n/* ś /* ł */ */",
+		"print \"\\\"quoted\\\" \\u{1F600}\"; \"bad \\q\"",
+		"print \"a ${ \"b ${ 1 /* } */ }\" } c\";",
+		"0x1F 1_000 2.5E3 0b12 1__0",
+	}
+
+	fixtures, err := filepath.Glob("../../fixtures/*")
+	if err != nil {
+		t.Fatalf("failed to list fixtures: %v", err)
+	}
+
+	for _, fixture := range fixtures {
+		content, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+
+		inputs = append(inputs, string(content))
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			l := lox.NewLox(lox.WithTrivia())
+
+			result, err := l.Tokenize(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			source := result.Source()
+			if source != input {
+				t.Errorf("\nexpected source:\n%q\ngot:\n%q\n", input, source)
+			}
+		})
+	}
+}
+
+func TestTokenizeTriviaAttachment(t *testing.T) {
+	input := "print 1; // one\n\n// two\nprint 2;"
+
+	l := lox.NewLox(lox.WithTrivia())
+	result, err := l.Tokenize(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	semicolon := result.Tokens[2]
+	if len(semicolon.TrailingTrivia) != 2 || semicolon.TrailingTrivia[1].Kind != lox.COMMENT || semicolon.TrailingTrivia[1].Text != "// one" {
+		t.Errorf("expected the trailing comment to be attached to the semicolon, got: %+v", semicolon.TrailingTrivia)
+	}
+
+	secondPrint := result.Tokens[3]
+	expectedKinds := []string{"NEWLINE", "COMMENT", "NEWLINE"}
+	if len(secondPrint.LeadingTrivia) != len(expectedKinds) {
+		t.Fatalf("expected %v leading trivia, got: %+v", len(expectedKinds), secondPrint.LeadingTrivia)
+	}
+
+	for i, kind := range expectedKinds {
+		if string(secondPrint.LeadingTrivia[i].Kind) != kind {
+			t.Errorf("expected leading trivia %v to be %v, got: %v", i, kind, secondPrint.LeadingTrivia[i].Kind)
+		}
+	}
+
+	result, err = lox.NewLox().Tokenize(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, token := range result.Tokens {
+		if len(token.LeadingTrivia) > 0 || len(token.TrailingTrivia) > 0 {
+			t.Errorf("did not expect trivia without the option, got: %+v", token)
+		}
+	}
+}
+
+func TestTokenizeColumns(t *testing.T) {
+	input := "print zażółć + 1;\n  \"ॐ\" ==\n\"multi\nline\" x"
+	expected := [][2]int{{1, 1}, {1, 7}, {1, 14}, {1, 16}, {1, 17}, {2, 3}, {2, 7}, {3, 1}, {4, 7}, {4, 8}}
+
+	l := lox.NewLox()
+	result, err := l.Tokenize(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Tokens) != len(expected) {
+		t.Fatalf("expected %v tokens, got: %v", len(expected), len(result.Tokens))
+	}
+
+	for i, token := range result.Tokens {
+		if token.Line != expected[i][0] || token.Column != expected[i][1] {
+			t.Errorf("expected %q at %v:%v, got: %v:%v", *token.Lexeme, expected[i][0], expected[i][1], token.Line, token.Column)
+		}
+	}
+
+	result, err = l.Tokenize(strings.NewReader("ab →\n  #"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Errors) != 2 || result.Errors[0].Column != 4 || result.Errors[1].Column != 3 {
+		t.Errorf("expected errors at columns 4 and 3, got: %+v", result.Errors)
+	}
+}
+
+func TestScanner(t *testing.T) {
+	input := "print #1;"
+
+	scanner := lox.NewScanner(strings.NewReader(input))
+
+	var tokens []lox.Token
+	var errs []error
+	for token, err := range scanner.All() {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	expectedTypes := []lox.TokenType{lox.PRINT, lox.NUMBER, lox.SEMICOLON, lox.EOF}
+	if len(tokens) != len(expectedTypes) {
+		t.Fatalf("expected %v tokens, got: %v", len(expectedTypes), tokens)
+	}
+
+	for i, token := range tokens {
+		if token.Type != expectedTypes[i] {
+			t.Errorf("expected token %v to be %v, got: %v", i, expectedTypes[i], token.Type)
+		}
+	}
+
+	if tokens[1].Literal != int64(1) || tokens[1].Column != 8 {
+		t.Errorf("expected number 1 at column 8, got: %+v", tokens[1])
+	}
+
+	if len(errs) != 1 || errs[0].Error() != "[line 1] Error: Unexpected character: #\n" {
+		t.Errorf("expected a single unexpected character error, got: %v", errs)
+	}
+
+	_, err := scanner.Next()
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF after the EOF token, got: %v", err)
+	}
+}
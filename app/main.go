@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -16,6 +19,12 @@ const (
 	CMD_PARSE    = "parse"
 	CMD_EVALUATE = "evaluate"
 	CMD_RUN      = "run"
+	CMD_FORMAT   = "fmt"
+)
+
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
 )
 
 func main() {
@@ -27,13 +36,11 @@ func main() {
 	switch cmd {
 	case CMD_TOKENIZE:
 		{
-			filePath := os.Args[2]
-			tokenize(filePath)
+			tokenize(os.Args[2:])
 		}
 	case CMD_PARSE:
 		{
-			filePath := os.Args[2]
-			parse(filePath)
+			parse(os.Args[2:])
 		}
 	case CMD_EVALUATE:
 		{
@@ -45,6 +52,10 @@ func main() {
 			filePath := os.Args[2]
 			run(filePath)
 		}
+	case CMD_FORMAT:
+		{
+			format(os.Args[2:])
+		}
 	default:
 		{
 			logger.Fatalf("Unknown command %s\n", os.Args[1])
@@ -53,22 +64,25 @@ func main() {
 }
 
 func run(filePath string) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		logger.Fatalf("Failed to read file: %v", err)
-	}
+	l := lox.NewLox()
+	file := openSource(l, filePath)
 	defer file.Close()
 
-	l := lox.NewLox()
-	err = l.Run(file, os.Stdout)
+	var err error
+	if filePath == "-" {
+		// The input might be a pipe, so every statement runs as soon as it is available.
+		err = l.RunStream(file)
+	} else {
+		err = l.Execute(file)
+	}
 	if err != nil {
 		if errors.As(err, &lox.RuntimeError{}) {
-			fmt.Fprint(os.Stderr, err.Error())
+			fmt.Fprint(l.Stderr(), err.Error())
 			os.Exit(70)
 		}
 
-		if (errors.As(err, &lox.SyntaxError{})) {
-			fmt.Fprint(os.Stderr, err.Error())
+		if errors.As(err, &lox.SyntaxError{}) || errors.As(err, &lox.UnexpectedTokenError{}) {
+			fmt.Fprint(l.Stderr(), err.Error())
 			os.Exit(65)
 		}
 
@@ -77,17 +91,19 @@ func run(filePath string) {
 }
 
 func evaluate(filePath string) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		logger.Fatalf("Failed to read file: %v", err)
-	}
+	l := lox.NewLox()
+	file := openSource(l, filePath)
 	defer file.Close()
 
-	l := lox.NewLox()
 	out, err := l.Evaluate(file)
 	if err != nil {
+		if errors.As(err, &lox.SyntaxError{}) || errors.As(err, &lox.UnexpectedTokenError{}) {
+			fmt.Fprint(l.Stderr(), err.Error())
+			os.Exit(65)
+		}
+
 		if errors.As(err, &lox.RuntimeError{}) {
-			fmt.Fprint(os.Stderr, err.Error())
+			fmt.Fprint(l.Stderr(), err.Error())
 			os.Exit(70)
 		}
 
@@ -97,60 +113,194 @@ func evaluate(filePath string) {
 	if out == nil {
 		// By default, nil formats as <nil>.
 		// I could not find any formatting verbs to format is as just "nil"
-		fmt.Fprint(os.Stdout, "nil")
+		fmt.Fprint(l.Stdout(), "nil")
 	} else {
-		fmt.Fprint(os.Stdout, out)
+		fmt.Fprint(l.Stdout(), out)
 	}
 }
 
-func parse(filePath string) {
-	file, err := os.Open(filePath)
+// format prints the formatted source of the file.
+// With the --check flag, it prints nothing and exits with 1 if the file is not formatted.
+func format(args []string) {
+	flags := flag.NewFlagSet(CMD_FORMAT, flag.ExitOnError)
+	check := flags.Bool("check", false, "exit with non-zero status if the file is not formatted")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		logger.Fatal("Missing arguments")
+	}
+
+	filePath := flags.Arg(0)
+	l := lox.NewLox()
+	file := openSource(l, filePath)
+	defer file.Close()
+
+	source, err := io.ReadAll(file)
 	if err != nil {
 		logger.Fatalf("Failed to read file: %v", err)
 	}
-	defer file.Close()
 
-	l := lox.NewLox()
-	expr, err := l.ParseExpression(file)
+	out, err := l.FormatSource(bytes.NewReader(source))
 	if err != nil {
-		if errors.As(err, &lox.SyntaxError{}) {
-			fmt.Fprint(os.Stderr, err.Error())
+		if errors.As(err, &lox.SyntaxError{}) || errors.As(err, &lox.UnexpectedTokenError{}) {
+			fmt.Fprint(l.Stderr(), err.Error())
 			os.Exit(65)
 		}
 
-		logger.Fatalf("Failed to parse the file: %v", err)
+		logger.Fatalf("Failed to format the file: %v", err)
 	}
 
-	out, err := lox.FormatExpression(expr)
-	if err != nil {
-		panic(err)
+	if !*check {
+		fmt.Fprint(l.Stdout(), out)
+		return
 	}
 
-	fmt.Fprint(os.Stdout, out)
+	if out != string(source) {
+		fmt.Fprintf(l.Stderr(), "%s is not formatted\n", filePath)
+		os.Exit(1)
+	}
 }
 
-func tokenize(filePath string) {
-	file, err := os.Open(filePath)
+func parse(args []string) {
+	filePath, outputFormat := parseOutputFlags(CMD_PARSE, args)
+
+	l := lox.NewLox()
+	file := openSource(l, filePath)
+	defer file.Close()
+
+	source, err := io.ReadAll(file)
 	if err != nil {
 		logger.Fatalf("Failed to read file: %v", err)
 	}
-	defer file.Close()
 
-	l := lox.NewLox()
-	result, err := l.Tokenize(file)
+	var out string
+	statements, err := l.Parse(bytes.NewReader(source))
+	if err == nil {
+		if outputFormat == FORMAT_JSON {
+			out, err = lox.FormatStatementsJSON(statements)
+		} else {
+			out, err = lox.Format(statements)
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Fprint(l.Stdout(), out)
+		return
+	}
+
+	// A single expression without the semicolon is not a valid program, but it is still printed on its own.
+	expr, exprErr := l.ParseExpression(bytes.NewReader(source))
+	if exprErr != nil {
+		var syntaxError lox.SyntaxError
+		var tokenError lox.UnexpectedTokenError
+		if errors.As(err, &syntaxError) || errors.As(err, &tokenError) {
+			if outputFormat == FORMAT_JSON {
+				var out string
+				if errors.As(err, &syntaxError) {
+					out, err = lox.FormatSyntaxErrorJSON(syntaxError)
+				} else {
+					out, err = lox.FormatTokenErrorJSON(tokenError)
+				}
+				if err != nil {
+					panic(err)
+				}
+				fmt.Fprint(l.Stdout(), out)
+			} else {
+				fmt.Fprint(l.Stderr(), err.Error())
+			}
+			os.Exit(65)
+		}
+
+		logger.Fatalf("Failed to parse the file: %v", err)
+	}
+
+	if outputFormat == FORMAT_JSON {
+		out, err = lox.FormatExpressionJSON(expr)
+	} else {
+		out, err = lox.FormatExpression(expr)
+	}
 	if err != nil {
-		logger.Fatalf("Failed to execute command: %v", err)
+		panic(err)
 	}
 
-	for _, token := range result.Tokens {
-		os.Stdout.Write([]byte(token.String()))
+	fmt.Fprint(l.Stdout(), out)
+}
+
+func tokenize(args []string) {
+	filePath, outputFormat := parseOutputFlags(CMD_TOKENIZE, args)
+
+	l := lox.NewLox()
+	file := openSource(l, filePath)
+	defer file.Close()
+
+	if outputFormat == FORMAT_JSON {
+		result, err := l.Tokenize(file)
+		if err != nil {
+			logger.Fatalf("Failed to execute command: %v", err)
+		}
+
+		out, err := lox.FormatTokensJSON(result)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Fprint(l.Stdout(), out)
+		if len(result.Errors) > 0 {
+			os.Exit(65)
+		}
+		return
 	}
 
-	for _, tokenError := range result.Errors {
-		os.Stderr.Write([]byte(tokenError.Error()))
+	// The tokens are printed as soon as they are scanned.
+	hasErrors := false
+	for token, err := range l.Scanner(file).All() {
+		if err != nil {
+			if !errors.As(err, &lox.UnexpectedTokenError{}) {
+				logger.Fatalf("Failed to execute command: %v", err)
+			}
+
+			hasErrors = true
+			l.Stderr().Write([]byte(err.Error()))
+			continue
+		}
+
+		l.Stdout().Write([]byte(token.String()))
 	}
 
-	if len(result.Errors) > 0 {
+	if hasErrors {
 		os.Exit(65)
 	}
 }
+
+// parseOutputFlags parses the arguments of the commands that support the --format flag.
+// It returns the file path and the output format.
+func parseOutputFlags(cmd string, args []string) (string, string) {
+	flags := flag.NewFlagSet(cmd, flag.ExitOnError)
+	outputFormat := flags.String("format", FORMAT_TEXT, "output format, either \"text\" or \"json\"")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		logger.Fatal("Missing arguments")
+	}
+
+	if *outputFormat != FORMAT_TEXT && *outputFormat != FORMAT_JSON {
+		logger.Fatalf("Unknown format %s\n", *outputFormat)
+	}
+
+	return flags.Arg(0), *outputFormat
+}
+
+// The "-" file path reads the source from the stdin of the Lox instance.
+func openSource(l *lox.Lox, filePath string) io.ReadCloser {
+	if filePath == "-" {
+		return io.NopCloser(l.Stdin())
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		logger.Fatalf("Failed to read file: %v", err)
+	}
+
+	return file
+}